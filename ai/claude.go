@@ -1,20 +1,30 @@
 package ai
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/tomswokowski/shippost/git"
 )
 
-// validGitHash matches a valid git commit hash (7-40 hex characters)
-var validGitHash = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+// killWaitDelay bounds how long we wait for output after killing claude,
+// in case it left child processes holding its pipes open
+const killWaitDelay = 2 * time.Second
+
+// Options holds optional inputs for post generation
+type Options struct {
+	PastPosts  []string            // Previously published posts, oldest first, used as style examples
+	OnProgress func(text string)   // Called with the partial response while Claude is writing
+	OnStatus   func(status string) // Called when a multi-pass generation moves to its next step
+}
 
 // GeneratePostSuggestion uses Claude Code CLI to generate a post suggestion
 // Returns a slice of posts (thread) - may be single post or multiple
-func GeneratePostSuggestion(commits []git.Commit, prompt string, allowThread bool) ([]string, error) {
+func GeneratePostSuggestion(ctx context.Context, commits []git.Commit, prompt string, allowThread bool, opts Options) ([]string, error) {
 	if len(commits) == 0 {
 		return nil, fmt.Errorf("no commits provided")
 	}
@@ -29,8 +39,12 @@ func GeneratePostSuggestion(commits []git.Commit, prompt string, allowThread boo
 		context.WriteString("\n\n")
 	}
 
+	multiRepo := spansRepos(commits)
 	for i, commit := range commits {
 		context.WriteString(fmt.Sprintf("Commit %d:\n", i+1))
+		if multiRepo {
+			context.WriteString(fmt.Sprintf("  Repository: %s\n", commit.Repo.Name))
+		}
 		context.WriteString(fmt.Sprintf("  Message: %s\n", commit.Subject))
 		if commit.Body != "" {
 			context.WriteString(fmt.Sprintf("  Details: %s\n", commit.Body))
@@ -39,36 +53,56 @@ func GeneratePostSuggestion(commits []git.Commit, prompt string, allowThread boo
 		context.WriteString("\n")
 	}
 
+	relevantTo := prompt
+	for _, commit := range commits {
+		relevantTo += " " + commit.Subject + " " + commit.Body
+	}
+	writeStyleExamples(&context, opts.PastPosts, relevantTo)
 	writeOutputFormat(&context, allowThread)
 
-	return runClaude(context.String())
+	return runClaude(ctx, context.String(), opts.OnProgress)
 }
 
-// GenerateFromQuery uses natural language query to generate a post from commits
+// GenerateFromQuery uses natural language query to generate a post from commits.
+// Claude first picks the commits relevant to the query, calling more for
+// older history when the query reaches past the commits given, then writes
+// the post from those commits' diffstats.
 // Returns a slice of posts (thread) - may be single post or multiple
-func GenerateFromQuery(query string, commits []git.Commit, allowThread bool) ([]string, error) {
+func GenerateFromQuery(ctx context.Context, query string, commits []git.Commit, more MoreCommits, allowThread bool, opts Options) ([]string, error) {
 	if query == "" {
 		return nil, fmt.Errorf("no query provided")
 	}
 
+	relevant, err := selectCommits(ctx, query, commits, more, opts.OnStatus)
+	if err != nil {
+		return nil, err
+	}
+	if opts.OnStatus != nil {
+		opts.OnStatus(fmt.Sprintf("Claude is writing about %d commit(s)...", len(relevant)))
+	}
+
 	var context strings.Builder
 	context.WriteString("You are helping a developer write an engaging X (Twitter) post about their coding work.\n\n")
 	context.WriteString("Their question/request: ")
 	context.WriteString(query)
 	context.WriteString("\n\n")
-	context.WriteString("Here are their recent git commits with diffs:\n\n")
+	context.WriteString("Here are the git commits relevant to it, with the files they changed:\n\n")
 
-	for i, commit := range commits {
-		if i >= 20 { // Limit to 20 commits
-			break
+	multiRepo := spansRepos(relevant)
+	for _, commit := range relevant {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if multiRepo {
+			context.WriteString(fmt.Sprintf("--- Commit in %s: %s (%s) ---\n", commit.Repo.Name, commit.Subject, commit.Ago))
+		} else {
+			context.WriteString(fmt.Sprintf("--- Commit: %s (%s) ---\n", commit.Subject, commit.Ago))
 		}
-		context.WriteString(fmt.Sprintf("--- Commit: %s (%s) ---\n", commit.Subject, commit.Ago))
 
 		// Get diff for this commit (validate hash first to prevent command injection)
-		if validGitHash.MatchString(commit.Hash) {
-			diffCmd := exec.Command("git", "show", "--stat", "--no-color", commit.Hash)
-			if diffOutput, err := diffCmd.Output(); err == nil {
-				context.WriteString(string(diffOutput))
+		if git.ValidHash.MatchString(commit.Hash) && commit.Repo.Repository != nil {
+			if stat, err := commit.Repo.GetCommitStat(commit.Hash); err == nil {
+				context.WriteString(stat)
 			}
 		}
 		context.WriteString("\n")
@@ -76,9 +110,20 @@ func GenerateFromQuery(query string, commits []git.Commit, allowThread bool) ([]
 
 	context.WriteString("\n")
 	writePromptRules(&context, allowThread)
+	writeStyleExamples(&context, opts.PastPosts, query)
 	writeOutputFormat(&context, allowThread)
 
-	return runClaude(context.String())
+	return runClaude(ctx, context.String(), opts.OnProgress)
+}
+
+// spansRepos reports whether commits come from more than one repository
+func spansRepos(commits []git.Commit) bool {
+	for _, c := range commits {
+		if c.Repo.Dir != commits[0].Repo.Dir {
+			return true
+		}
+	}
+	return false
 }
 
 // IsClaudeAvailable checks if the claude CLI is installed and accessible
@@ -117,17 +162,46 @@ func writeOutputFormat(b *strings.Builder, allowThread bool) {
 }
 
 // runClaude executes the claude CLI and parses the response
-func runClaude(prompt string) ([]string, error) {
-	cmd := exec.Command("claude", "-p", prompt)
-	output, err := cmd.Output()
+// The process is killed when ctx is cancelled or its deadline passes.
+// If onProgress is set, the response is streamed and reported as it arrives.
+func runClaude(ctx context.Context, prompt string, onProgress func(string)) ([]string, error) {
+	output, err := runClaudeText(ctx, prompt, onProgress)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("claude error: %s", string(exitErr.Stderr))
+		return nil, err
+	}
+	return parseThreadResponse(output), nil
+}
+
+// runClaudeText executes the claude CLI and returns its raw response
+func runClaudeText(ctx context.Context, prompt string, onProgress func(string)) (string, error) {
+	var output string
+	var err error
+	if onProgress != nil {
+		output, err = runClaudeStreaming(ctx, prompt, onProgress)
+	} else {
+		cmd := exec.CommandContext(ctx, "claude", "-p", prompt)
+		cmd.WaitDelay = killWaitDelay
+		var out []byte
+		out, err = cmd.Output()
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("claude error: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		} else if err != nil {
+			err = fmt.Errorf("failed to run claude: %w", err)
 		}
-		return nil, fmt.Errorf("failed to run claude: %w", err)
+		output = string(out)
 	}
 
-	return parseThreadResponse(string(output)), nil
+	// Report cancellation and timeouts rather than the resulting "signal: killed"
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("claude timed out: %w", ctx.Err())
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return "", err
+	}
+	return output, nil
 }
 
 // parseThreadResponse splits the AI response into individual posts
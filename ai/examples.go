@@ -0,0 +1,198 @@
+package ai
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxStyleExamples is how many past posts are shown to the model
+	maxStyleExamples = 5
+
+	// duplicateThreshold is the similarity above which a suggestion is
+	// considered a near-duplicate of a published post
+	duplicateThreshold = 0.6
+)
+
+// stopWords are ignored when comparing posts
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true,
+	"this": true, "from": true, "into": true, "are": true, "was": true,
+	"have": true, "has": true, "just": true, "now": true, "our": true,
+	"you": true, "your": true, "not": true, "but": true, "can": true,
+	"its": true, "it's": true, "all": true, "out": true, "new": true,
+}
+
+// Duplicate describes a published post that a suggestion closely resembles
+type Duplicate struct {
+	Text       string
+	Similarity float64
+}
+
+// SelectExamples picks the past posts most relevant to the given text,
+// to be used as style examples. Falls back to the most recent posts
+// when nothing overlaps. pastPosts must be ordered oldest first.
+func SelectExamples(pastPosts []string, text string, n int) []string {
+	if len(pastPosts) == 0 || n <= 0 {
+		return nil
+	}
+
+	queryTokens := tokenSet(text)
+	idf := inverseDocFrequency(pastPosts)
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var candidates []scored
+	for i, post := range pastPosts {
+		var score float64
+		for tok := range tokenSet(post) {
+			if queryTokens[tok] {
+				score += idf[tok]
+			}
+		}
+		if score > 0 {
+			candidates = append(candidates, scored{i, score})
+		}
+	}
+
+	// Highest score first; newer posts win ties
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].score != candidates[b].score {
+			return candidates[a].score > candidates[b].score
+		}
+		return candidates[a].index > candidates[b].index
+	})
+
+	picked := make(map[int]bool)
+	var examples []string
+	for _, c := range candidates {
+		if len(examples) >= n {
+			break
+		}
+		picked[c.index] = true
+		examples = append(examples, pastPosts[c.index])
+	}
+
+	// Top up with the most recent posts so the model always sees our voice
+	for i := len(pastPosts) - 1; i >= 0 && len(examples) < n; i-- {
+		if !picked[i] {
+			examples = append(examples, pastPosts[i])
+		}
+	}
+
+	return examples
+}
+
+// FindDuplicate returns the published post most similar to text, if the
+// similarity is above the near-duplicate threshold
+func FindDuplicate(text string, pastPosts []string) (Duplicate, bool) {
+	shingles := shingleSet(text)
+	if len(shingles) == 0 {
+		return Duplicate{}, false
+	}
+
+	var best Duplicate
+	for _, post := range pastPosts {
+		sim := jaccard(shingles, shingleSet(post))
+		if sim > best.Similarity {
+			best = Duplicate{Text: post, Similarity: sim}
+		}
+	}
+
+	if best.Similarity < duplicateThreshold {
+		return Duplicate{}, false
+	}
+	return best, true
+}
+
+// writeStyleExamples writes a few relevant past posts into the prompt
+func writeStyleExamples(b *strings.Builder, pastPosts []string, relevantTo string) {
+	examples := SelectExamples(pastPosts, relevantTo, maxStyleExamples)
+	if len(examples) == 0 {
+		return
+	}
+
+	b.WriteString("STYLE EXAMPLES - posts we have already published. Match their tone, length and formatting, but do NOT repeat them:\n")
+	for _, example := range examples {
+		b.WriteString("<example>\n")
+		b.WriteString(example)
+		b.WriteString("\n</example>\n")
+	}
+	b.WriteString("\n")
+}
+
+// tokenize lowercases text and splits it into words, dropping URLs,
+// stop words and very short tokens
+func tokenize(text string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+			continue
+		}
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len([]rune(word)) < 3 || stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+func tokenSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, tok := range tokenize(text) {
+		set[tok] = true
+	}
+	return set
+}
+
+// shingleSet returns the set of word pairs in text, which captures
+// phrasing better than single words when comparing for duplicates
+func shingleSet(text string) map[string]bool {
+	tokens := tokenize(text)
+	set := make(map[string]bool)
+	if len(tokens) == 1 {
+		set[tokens[0]] = true
+	}
+	for i := 0; i+1 < len(tokens); i++ {
+		set[tokens[i]+" "+tokens[i+1]] = true
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	intersection := 0
+	for k := range a {
+		if b[k] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// inverseDocFrequency weights rare words higher than words used in every post
+func inverseDocFrequency(docs []string) map[string]float64 {
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		for tok := range tokenSet(doc) {
+			counts[tok]++
+		}
+	}
+
+	idf := make(map[string]float64, len(counts))
+	n := float64(len(docs))
+	for tok, count := range counts {
+		idf[tok] = math.Log(1 + n/float64(count))
+	}
+	return idf
+}
@@ -0,0 +1,119 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomswokowski/shippost/git"
+)
+
+// maxSelectedCommits is how many commits Ask mode writes about, with their
+// diffstats
+const maxSelectedCommits = 20
+
+// maxSelectRounds bounds how many times Claude may ask for older history
+// before it has to pick from what it has
+const maxSelectRounds = 3
+
+// MoreCommits returns the next, older page of commits, or none once the
+// history is exhausted
+type MoreCommits func() ([]git.Commit, error)
+
+// selectCommits asks Claude which commits a query is about. Claude sees one
+// line per commit and may answer MORE to be shown older ones. When it picks
+// nothing, the most recent commits are used.
+func selectCommits(ctx context.Context, query string, commits []git.Commit, more MoreCommits, onStatus func(string)) ([]git.Commit, error) {
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+
+	exhausted := more == nil
+	for round := 0; ; round++ {
+		if onStatus != nil {
+			onStatus(fmt.Sprintf("Claude is picking from %d commits...", len(commits)))
+		}
+		canAskMore := !exhausted && round < maxSelectRounds
+		output, err := runClaudeText(ctx, selectPrompt(query, commits, canAskMore, time.Now()), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		picked, wantsMore := parseSelection(output, commits)
+		if wantsMore && canAskMore {
+			if onStatus != nil {
+				onStatus("Loading older commits...")
+			}
+			older, err := more()
+			if err != nil {
+				return nil, err
+			}
+			if len(older) == 0 {
+				exhausted = true
+			}
+			commits = append(commits[:len(commits):len(commits)], older...)
+			continue
+		}
+
+		if len(picked) == 0 {
+			return commits[:min(len(commits), maxSelectedCommits)], nil
+		}
+		return picked, nil
+	}
+}
+
+// selectPrompt lists the commits, newest first, for Claude to pick from
+func selectPrompt(query string, commits []git.Commit, canAskMore bool, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("A developer wants to write an X (Twitter) post about their coding work.\n\n")
+	b.WriteString("Their question/request: ")
+	b.WriteString(query)
+	b.WriteString("\n\n")
+	if spansRepos(commits) {
+		b.WriteString(fmt.Sprintf("Today is %s. Here are their git commits across several repositories, newest first, as hash, date, [repository], subject and author:\n\n", now.Format("2006-01-02 (Monday)")))
+		for _, c := range commits {
+			b.WriteString(fmt.Sprintf("%s %s [%s] %s (%s)\n", c.Hash, c.Timestamp.Format("2006-01-02"), c.Repo.Name, c.Subject, c.Author))
+		}
+	} else {
+		b.WriteString(fmt.Sprintf("Today is %s. Here are their git commits, newest first, as hash, date, subject and author:\n\n", now.Format("2006-01-02 (Monday)")))
+		for _, c := range commits {
+			b.WriteString(fmt.Sprintf("%s %s %s (%s)\n", c.Hash, c.Timestamp.Format("2006-01-02"), c.Subject, c.Author))
+		}
+	}
+
+	b.WriteString("\nPick the commits the request is about.\n\n")
+	b.WriteString("OUTPUT FORMAT:\n")
+	b.WriteString(fmt.Sprintf("- Output only the hashes of the relevant commits, one per line, most relevant first, at most %d\n", maxSelectedCommits))
+	if canAskMore {
+		b.WriteString("- If the request is about work older than the oldest commit listed, output only the word MORE to see older commits\n")
+	}
+	b.WriteString("- If nothing is clearly relevant, output the word NONE\n")
+	b.WriteString("- No other text\n")
+	return b.String()
+}
+
+// parseSelection reads the hashes Claude picked, ignoring any it made up,
+// and whether it asked for more history
+func parseSelection(output string, commits []git.Commit) (picked []git.Commit, wantsMore bool) {
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(output) {
+		word = strings.Trim(word, "-*•.,:;`'\"()[]")
+		if strings.EqualFold(word, "MORE") {
+			wantsMore = true
+			continue
+		}
+		if !git.ValidHash.MatchString(word) || len(picked) >= maxSelectedCommits {
+			continue
+		}
+		word = strings.ToLower(word)
+		for _, c := range commits {
+			if strings.HasPrefix(word, strings.ToLower(c.Hash)) && !seen[c.Hash] {
+				seen[c.Hash] = true
+				picked = append(picked, c)
+				break
+			}
+		}
+	}
+	return picked, wantsMore
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// maxStreamLine is the largest single JSON event accepted from the claude CLI
+const maxStreamLine = 10 * 1024 * 1024
+
+// streamEvent is the subset of the claude CLI stream-json output we read
+type streamEvent struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	Result  string `json:"result"`
+	IsError bool   `json:"is_error"`
+	Event   struct {
+		Type  string `json:"type"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+	} `json:"event"`
+	Message struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+// runClaudeStreaming runs the claude CLI with streaming JSON output, calling
+// onProgress with the text received so far, and returns the final response
+func runClaudeStreaming(ctx context.Context, prompt string, onProgress func(string)) (string, error) {
+	cmd := exec.CommandContext(ctx, "claude", "-p", prompt,
+		"--output-format", "stream-json", "--verbose", "--include-partial-messages")
+
+	cmd.WaitDelay = killWaitDelay
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to run claude: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to run claude: %w", err)
+	}
+
+	var partial strings.Builder
+	var result string
+	var resultErr bool
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		var ev streamEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue // ignore anything that isn't an event
+		}
+
+		switch ev.Type {
+		case "stream_event":
+			if ev.Event.Type == "content_block_delta" && ev.Event.Delta.Type == "text_delta" {
+				partial.WriteString(ev.Event.Delta.Text)
+				onProgress(partial.String())
+			}
+		case "assistant":
+			// Complete message - used when partial messages aren't supported
+			var text strings.Builder
+			for _, c := range ev.Message.Content {
+				if c.Type == "text" {
+					text.WriteString(c.Text)
+				}
+			}
+			if text.Len() > 0 && partial.Len() == 0 {
+				onProgress(text.String())
+			}
+		case "result":
+			result = ev.Result
+			resultErr = ev.IsError
+		}
+	}
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		io.Copy(io.Discard, stdout) // unblock the process so Wait can return
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if stderr.Len() > 0 {
+			return "", fmt.Errorf("claude error: %s", strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("failed to run claude: %w", err)
+	}
+	if scanErr != nil {
+		return "", fmt.Errorf("failed to read claude output: %w", scanErr)
+	}
+	if resultErr {
+		return "", fmt.Errorf("claude error: %s", result)
+	}
+
+	if result == "" {
+		result = partial.String()
+	}
+	return result, nil
+}
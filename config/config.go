@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/term"
 )
@@ -15,23 +16,74 @@ import (
 const (
 	configDirPerm  = 0700
 	configFilePerm = 0600
+
+	// defaultAITimeout is how long AI generation may run before it is cancelled
+	defaultAITimeout = 2 * time.Minute
 )
 
-// Config holds the X API credentials
+// Config holds the X API credentials and user preferences
 type Config struct {
 	APIKey       string `json:"api_key"`
 	APISecret    string `json:"api_secret"`
 	AccessToken  string `json:"access_token"`
 	AccessSecret string `json:"access_secret"`
+
+	AITimeoutSeconds int      `json:"ai_timeout_seconds,omitempty"`
+	SnippetTheme     string   `json:"snippet_theme,omitempty"`
+	Theme            string   `json:"theme,omitempty"`       // built-in theme or a file in themes/
+	Repos            []string `json:"repos,omitempty"`       // repositories Smart Post reads commits from
+	GitBackend       string   `json:"git_backend,omitempty"` // "exec" or "go-git"; default exec when git is installed
+
+	Media MediaConfig `json:"media"`
+	Hook  HookConfig  `json:"hook"`
 }
 
-// configPath returns the path to the config file
-func configPath() (string, error) {
+// HookConfig holds the rules the git hooks use to decide what is worth a
+// post. Unset lists use the defaults: feat commits and v* tags.
+type HookConfig struct {
+	CommitTypes []string `json:"commit_types,omitempty"` // conventional commit types, or "*" for every commit
+	Tags        []string `json:"tags,omitempty"`         // tag name patterns like v*
+}
+
+// MediaConfig controls how images are processed before upload.
+// Unset fields use the defaults: every step enabled with X's limits.
+type MediaConfig struct {
+	Resize        *bool `json:"resize,omitempty"`
+	MaxDimension  int   `json:"max_dimension,omitempty"`
+	Compress      *bool `json:"compress,omitempty"`
+	MaxBytes      int64 `json:"max_bytes,omitempty"`
+	StripMetadata *bool `json:"strip_metadata,omitempty"`
+	JPEGQuality   int   `json:"jpeg_quality,omitempty"`
+}
+
+// Dir returns the shippost config directory (~/.config/shippost)
+func Dir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	return filepath.Join(home, ".config", "shippost", "config.json"), nil
+	return filepath.Join(home, ".config", "shippost"), nil
+}
+
+// EnsureDir creates the config directory with secure permissions and returns its path
+func EnsureDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, configDirPerm); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return dir, nil
+}
+
+// configPath returns the path to the config file
+func configPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
 }
 
 // Exists checks if a valid config file exists
@@ -114,6 +166,14 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// AITimeout returns the maximum duration of a single AI generation
+func (c *Config) AITimeout() time.Duration {
+	if c.AITimeoutSeconds > 0 {
+		return time.Duration(c.AITimeoutSeconds) * time.Second
+	}
+	return defaultAITimeout
+}
+
 // IsValid checks if all required fields are present
 func (c *Config) IsValid() bool {
 	return c.APIKey != "" && c.APISecret != "" && c.AccessToken != "" && c.AccessSecret != ""
@@ -155,7 +215,13 @@ func RunSetup() error {
 
 	reader := bufio.NewReader(os.Stdin)
 
+	// Keep existing preferences - setup only replaces the credentials
 	cfg := &Config{}
+	if path, err := configPath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, cfg)
+		}
+	}
 
 	// API Key
 	fmt.Print("API Key (Consumer Key): ")
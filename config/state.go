@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// maxRecentDirs is how many recently used media directories are remembered
+const maxRecentDirs = 9
+
+// recentDirsPath returns the path to the recent media directories file
+func recentDirsPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recent_dirs.json"), nil
+}
+
+// RecentDirs returns directories media were recently attached from, most recent first
+func RecentDirs() []string {
+	path, err := recentDirsPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	json.Unmarshal(data, &dirs)
+	return dirs
+}
+
+// AddRecentDir moves dir to the front of the recent directories list
+func AddRecentDir(dir string) error {
+	dirs := []string{dir}
+	for _, d := range RecentDirs() {
+		if d != dir && len(dirs) < maxRecentDirs {
+			dirs = append(dirs, d)
+		}
+	}
+
+	if _, err := EnsureDir(); err != nil {
+		return err
+	}
+	path, err := recentDirsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(dirs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, configFilePerm)
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ThemeFile is a user-defined colour theme, stored as themes/<name>.json in
+// the config directory. Colors override individual slots of the Base theme.
+type ThemeFile struct {
+	Base   string                `json:"base,omitempty"`
+	Colors map[string]ThemeColor `json:"colors"`
+}
+
+// ThemeColor is a colour for light and dark terminal backgrounds. In a theme
+// file it is either a single colour for both, or {"light": ..., "dark": ...}.
+type ThemeColor struct {
+	Light string `json:"light"`
+	Dark  string `json:"dark"`
+}
+
+// UnmarshalJSON accepts a plain string as the same colour on both backgrounds
+func (c *ThemeColor) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		c.Light, c.Dark = s, s
+		return nil
+	}
+	type plain ThemeColor
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// LoadTheme reads the theme file with the given name. Returns nil if there
+// is no such file.
+func LoadTheme(name string) (*ThemeFile, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "themes", name+".json")
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+
+	var theme ThemeFile
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &theme, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tomswokowski/shippost/history"
+	"github.com/tomswokowski/shippost/x"
+	"golang.org/x/term"
+)
+
+// runDelete deletes a published post, or a whole thread, from the command line
+func runDelete(args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	thread := fs.Bool("thread", false, "Delete every post of the thread the post belongs to")
+	yes := fs.Bool("yes", false, "Don't ask for confirmation")
+	fs.Usage = func() {
+		fmt.Println("Usage:")
+		fmt.Println("  shippost delete [flags] <url|id>")
+		fmt.Println("  shippost delete [flags] last   Delete what you published most recently")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one post URL or ID, or \"last\"")
+	}
+
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+	posts, err := postsToDelete(entries, fs.Arg(0), *thread)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		text := post.Text
+		if text == "" {
+			text = "(not in your post history)"
+		}
+		fmt.Fprintf(os.Stderr, "%s  %s\n", x.PostURL(post.ID), strings.Join(strings.Fields(text), " "))
+	}
+	if !*yes {
+		ok, err := confirm(fmt.Sprintf("Delete %d post(s)? This can't be undone.", len(posts)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("cancelled")
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	deleted, deleteErr := x.DeleteThread(client, ids)
+
+	// Posts are deleted last to first, so the deleted ones are at the end
+	if !x.IsFake(client) {
+		if _, err := history.Remove(ids[len(ids)-deleted:]...); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update post history: %v\n", err)
+		}
+	}
+	if deleteErr != nil {
+		return deleteErr
+	}
+	fmt.Printf("Deleted %d post(s)\n", deleted)
+	return nil
+}
+
+// postsToDelete resolves the delete argument to posts, oldest first. Posts
+// missing from the history can still be deleted by ID, but without --thread.
+func postsToDelete(entries []history.Entry, ref string, wholeThread bool) ([]history.Entry, error) {
+	var post history.Entry
+	if ref == "last" {
+		last := history.LastPublished(entries)
+		if len(last) == 0 {
+			return nil, fmt.Errorf("no posts published with shippost in your history")
+		}
+		if !wholeThread {
+			return last, nil
+		}
+		post = last[0]
+	} else {
+		id, err := x.ParsePostID(ref)
+		if err != nil {
+			return nil, err
+		}
+		post = history.Entry{ID: id}
+		for _, e := range entries {
+			if e.ID == id {
+				post = e
+			}
+		}
+	}
+
+	if !wholeThread {
+		return []history.Entry{post}, nil
+	}
+	t, ok := history.FindThread(entries, post.ID)
+	if !ok {
+		return nil, fmt.Errorf("post %s is not in your post history, so its thread is unknown", post.ID)
+	}
+	return t.Posts, nil
+}
+
+// confirm asks a yes/no question on the terminal. Without a terminal there is
+// no one to ask, so --yes is required.
+func confirm(question string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("not a terminal - pass --yes to confirm")
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
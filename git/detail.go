@@ -0,0 +1,79 @@
+package git
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDiffLines caps the diff kept for a commit; huge generated or vendored
+// changes aren't worth reading in a terminal pane
+const maxDiffLines = 5000
+
+// Detail is everything shown about a single commit in the commit browser
+type Detail struct {
+	Hash      string
+	Author    string
+	Email     string
+	Date      time.Time
+	Message   string // full message: subject, blank line, body
+	Files     []FileChange
+	Diff      []string // unified diff lines, without colour
+	Truncated bool     // the diff was cut at maxDiffLines
+}
+
+// FileChange is a file touched by a commit with its changed line counts
+type FileChange struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool // binary files have no line counts
+}
+
+// Stat returns the total lines added and deleted across all files
+func (d *Detail) Stat() (added, deleted int) {
+	for _, f := range d.Files {
+		added += f.Added
+		deleted += f.Deleted
+	}
+	return added, deleted
+}
+
+// detailCache holds commit details by repository directory and hash. Commits
+// never change, so entries stay for the life of the process.
+var detailCache = struct {
+	sync.Mutex
+	details map[string]*Detail
+}{details: make(map[string]*Detail)}
+
+// GetCommitDetail returns the message, changed files and diff of a commit,
+// reading the repository only the first time a commit is asked for
+func (r Repo) GetCommitDetail(hash string) (*Detail, error) {
+	key := r.Dir + "\x00" + hash
+	detailCache.Lock()
+	d, ok := detailCache.details[key]
+	detailCache.Unlock()
+	if ok {
+		return d, nil
+	}
+
+	d, err := r.Repository.GetCommitDetail(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	detailCache.Lock()
+	detailCache.details[key] = d
+	detailCache.Unlock()
+	return d, nil
+}
+
+// setDiff keeps the lines of a unified diff, up to maxDiffLines
+func (d *Detail) setDiff(diff string) {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	if len(lines) > maxDiffLines {
+		lines = lines[:maxDiffLines]
+		d.Truncated = true
+	}
+	d.Diff = lines
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hunk is one changed region of a file in a commit's diff
+type Hunk struct {
+	File     string   // path of the file after the change
+	Header   string   // the "@@ -a,b +c,d @@ context" line
+	NewStart int      // line number of the first line in the new file
+	Lines    []string // diff lines, each prefixed with ' ', '+' or '-'
+}
+
+// Context returns the enclosing function or section git shows after the line range
+func (h Hunk) Context() string {
+	rest := strings.TrimPrefix(h.Header, "@@")
+	if i := strings.Index(rest, "@@"); i >= 0 {
+		return strings.TrimSpace(rest[i+2:])
+	}
+	return ""
+}
+
+// parseHunks splits unified diff output into hunks
+func parseHunks(diff string) []Hunk {
+	var hunks []Hunk
+	var file string
+	current := -1 // index of the hunk being read
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = -1
+			file = ""
+		case strings.HasPrefix(line, "--- a/") && file == "":
+			// Deleted files have no "+++ b/" path - fall back to the old one
+			file = strings.TrimPrefix(line, "--- a/")
+		case strings.HasPrefix(line, "+++ b/") && current < 0:
+			file = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "@@ "):
+			var newStart int
+			if i := strings.Index(line, " +"); i >= 0 {
+				fmt.Sscanf(line[i:], " +%d", &newStart)
+			}
+			hunks = append(hunks, Hunk{File: file, Header: line, NewStart: newStart})
+			current = len(hunks) - 1
+		case current >= 0 && line != "" && strings.ContainsRune(" +-", rune(line[0])):
+			hunks[current].Lines = append(hunks[current].Lines, line)
+		}
+	}
+	return hunks
+}
@@ -0,0 +1,199 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkHash returns an error for anything but a commit hash. Hashes are
+// checked before they reach git show, so one starting with "-" can't pass as
+// a flag.
+func checkHash(hash string) error {
+	if !ValidHash.MatchString(hash) {
+		return fmt.Errorf("invalid commit hash: %s", hash)
+	}
+	return nil
+}
+
+// execRepo is the Repository that runs the git binary
+type execRepo struct {
+	dir string
+}
+
+// openExec finds the repository containing dir with git rev-parse
+func openExec(dir string) (Repo, error) {
+	top, err := execRepo{dir: dir}.command("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return Repo{}, fmt.Errorf("%s is not a git repository", dir)
+	}
+	root := strings.TrimSpace(string(top))
+	return Repo{Name: filepath.Base(root), Dir: root, Repository: execRepo{dir: root}}, nil
+}
+
+// command returns a git command that runs in the repository
+func (r execRepo) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	return cmd
+}
+
+// GetCommitPage returns up to limit commits starting at the page cursor,
+// newest first, and the cursor for the page after them. An empty page at
+// the end of the history is not an error.
+func (r execRepo) GetCommitPage(page Page, limit int) ([]Commit, Page, error) {
+	if page.End {
+		return nil, page, nil
+	}
+
+	if page.Tip == "" {
+		tip, err := r.command("rev-parse", "--verify", "HEAD").Output()
+		if err != nil {
+			return nil, page, fmt.Errorf("no commits found")
+		}
+		page.Tip = strings.TrimSpace(string(tip))
+	}
+
+	// Get commits with format: hash|subject|author|timestamp|body, followed
+	// by the changed files from --name-only
+	// Each record starts with %x00 (null byte) to handle multi-line content,
+	// and %x02 separates the fields from the file list
+	format := "%x00%H%x01%s%x01%an%x01%at%x01%b%x02"
+	cmd := r.command("log", fmt.Sprintf("-%d", limit), fmt.Sprintf("--skip=%d", page.Skip),
+		fmt.Sprintf("--format=%s", format), "--name-only", "--no-merges", page.Tip)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	commits := parseLog(string(output))
+	page.Skip += len(commits)
+	page.End = len(commits) < limit
+	return commits, page, nil
+}
+
+// parseLog reads the records written by the format in GetCommitPage
+func parseLog(output string) []Commit {
+	// Split by null byte to get individual commits
+	records := strings.Split(strings.TrimSpace(output), "\x00")
+
+	var commits []Commit
+	for _, record := range records {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+
+		fields, names, _ := strings.Cut(record, "\x02")
+
+		// Split by unit separator (0x01)
+		parts := strings.Split(fields, "\x01")
+		if len(parts) < 5 {
+			continue
+		}
+
+		var timestamp time.Time
+		if ts, err := parseUnixTimestamp(parts[3]); err == nil {
+			timestamp = ts
+		}
+
+		commits = append(commits, Commit{
+			Hash:      parts[0][:7], // Short hash
+			Subject:   parts[1],
+			Body:      strings.TrimSpace(parts[4]),
+			Author:    parts[2],
+			Timestamp: timestamp,
+			Ago:       timeAgo(timestamp),
+			Files:     nonEmptyLines(names),
+		})
+	}
+	return commits
+}
+
+// GetCommitDetail returns the message, changed files and diff of a commit
+func (r execRepo) GetCommitDetail(hash string) (*Detail, error) {
+	if err := checkHash(hash); err != nil {
+		return nil, err
+	}
+	// Message and numstat in one call: the header ends with a null byte,
+	// followed by one "added<TAB>deleted<TAB>path" line per file
+	format := "%H%x01%an%x01%ae%x01%at%x01%B%x00"
+	output, err := r.command("show", "--numstat", "--no-color", fmt.Sprintf("--format=%s", format), hash).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+
+	header, numstat, _ := strings.Cut(string(output), "\x00")
+	parts := strings.SplitN(header, "\x01", 5)
+	if len(parts) < 5 {
+		return nil, fmt.Errorf("failed to parse commit %s", hash)
+	}
+
+	d := &Detail{
+		Hash:    parts[0],
+		Author:  parts[1],
+		Email:   parts[2],
+		Message: strings.TrimSpace(parts[4]),
+		Files:   parseNumstat(numstat),
+	}
+	if ts, err := parseUnixTimestamp(parts[3]); err == nil {
+		d.Date = ts
+	}
+
+	diff, err := r.command("show", "--format=", "--no-color", "--no-ext-diff", "--unified=3", hash).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff for %s: %w", hash, err)
+	}
+	d.setDiff(string(diff))
+	return d, nil
+}
+
+// parseNumstat reads `git show --numstat` lines. Binary files show "-" for
+// both counts.
+func parseNumstat(output string) []FileChange {
+	var files []FileChange
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		f := FileChange{Path: fields[2]}
+		if fields[0] == "-" && fields[1] == "-" {
+			f.Binary = true
+		} else {
+			f.Added, _ = strconv.Atoi(fields[0])
+			f.Deleted, _ = strconv.Atoi(fields[1])
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// GetCommitHunks returns the diff hunks introduced by a commit
+func (r execRepo) GetCommitHunks(hash string) ([]Hunk, error) {
+	if err := checkHash(hash); err != nil {
+		return nil, err
+	}
+	cmd := r.command("show", "--format=", "--no-color", "--no-ext-diff", "--unified=3", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff for %s: %w", hash, err)
+	}
+	return parseHunks(string(output)), nil
+}
+
+// GetCommitStat returns the subject and diffstat of a commit as git show
+// --stat prints them
+func (r execRepo) GetCommitStat(hash string) (string, error) {
+	if err := checkHash(hash); err != nil {
+		return "", err
+	}
+	output, err := r.command("show", "--stat", "--no-color", hash).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get stat for %s: %w", hash, err)
+	}
+	return string(output), nil
+}
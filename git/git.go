@@ -2,14 +2,17 @@ package git
 
 import (
 	"fmt"
-	"os/exec"
+	"regexp"
 	"strings"
 	"time"
 )
 
+// ValidHash matches a valid git commit hash (7-40 hex characters)
+var ValidHash = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
 // IsGitRepo checks if the current directory is inside a git repository
 func IsGitRepo() bool {
-	err := exec.Command("git", "rev-parse", "--git-dir").Run()
+	_, err := Open(".", BackendAuto)
 	return err == nil
 }
 
@@ -21,60 +24,61 @@ type Commit struct {
 	Author    string
 	Timestamp time.Time
 	Ago       string
+	Files     []string // paths changed by the commit
+	Repo      Repo     // repository the commit belongs to
+}
+
+// CommitType returns the conventional commit type of a subject, e.g. "feat"
+// for "feat(ui)!: add themes", or "" when it doesn't follow the convention
+func CommitType(subject string) string {
+	prefix, _, found := strings.Cut(subject, ":")
+	if !found {
+		return ""
+	}
+	prefix = strings.TrimSuffix(prefix, "!")
+	if i := strings.IndexByte(prefix, '('); i >= 0 && strings.HasSuffix(prefix, ")") {
+		prefix = prefix[:i]
+	}
+	if prefix == "" || strings.ContainsAny(prefix, " \t") {
+		return ""
+	}
+	return strings.ToLower(prefix)
 }
 
 // GetRecentCommits returns the most recent commits from the current repo
 func GetRecentCommits(limit int) ([]Commit, error) {
-	// Check if we're in a git repo
-	if err := exec.Command("git", "rev-parse", "--git-dir").Run(); err != nil {
+	repo, err := Open(".", BackendAuto)
+	if err != nil {
 		return nil, fmt.Errorf("not a git repository")
 	}
-
-	// Get commits with format: hash|subject|author|timestamp
-	// Use %x00 (null byte) as record separator to handle multi-line content
-	// Skip body in the list view - we only need subject for display
-	format := "%H%x01%s%x01%an%x01%at%x00"
-	cmd := exec.Command("git", "log", fmt.Sprintf("-%d", limit), fmt.Sprintf("--format=%s", format), "--no-merges")
-	output, err := cmd.Output()
+	commits, _, err := NewLog([]Repo{repo}).Next(limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commits: %w", err)
+		return nil, err
 	}
-
-	// Split by null byte to get individual commits
-	records := strings.Split(strings.TrimSpace(string(output)), "\x00")
-	if len(records) == 0 || (len(records) == 1 && records[0] == "") {
+	if len(commits) == 0 {
 		return nil, fmt.Errorf("no commits found")
 	}
+	return commits, nil
+}
 
-	var commits []Commit
-	for _, record := range records {
-		record = strings.TrimSpace(record)
-		if record == "" {
-			continue
-		}
-
-		// Split by unit separator (0x01)
-		parts := strings.Split(record, "\x01")
-		if len(parts) < 4 {
-			continue
-		}
+// Page is a cursor into the commit history. The first page pins Tip to the
+// commit HEAD pointed at, so later pages stay in step even if new commits
+// land while the user is scrolling.
+type Page struct {
+	Tip  string // full hash the history is read from; empty for the first page
+	Skip int    // commits already read from Tip
+	End  bool   // no older commits are left
+}
 
-		var timestamp time.Time
-		if ts, err := parseUnixTimestamp(parts[3]); err == nil {
-			timestamp = ts
+// nonEmptyLines splits s into lines, dropping blank ones
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
 		}
-
-		commits = append(commits, Commit{
-			Hash:      parts[0][:7], // Short hash
-			Subject:   parts[1],
-			Body:      "", // Body fetched separately if needed
-			Author:    parts[2],
-			Timestamp: timestamp,
-			Ago:       timeAgo(timestamp),
-		})
 	}
-
-	return commits, nil
+	return lines
 }
 
 func parseUnixTimestamp(s string) (time.Time, error) {
@@ -0,0 +1,252 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// goGitRepo is the Repository that reads the repository files with go-git,
+// for machines without git installed. go-git's storage isn't safe for
+// concurrent use, so every read holds the lock.
+type goGitRepo struct {
+	mu   sync.Mutex
+	repo *gogit.Repository
+}
+
+// openGoGit finds the repository containing dir, like git rev-parse would
+func openGoGit(dir string) (Repo, error) {
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return Repo{}, fmt.Errorf("%s is not a git repository", dir)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return Repo{}, fmt.Errorf("%s is not a git repository", dir)
+	}
+	root := worktree.Filesystem.Root()
+	return Repo{Name: filepath.Base(root), Dir: root, Repository: &goGitRepo{repo: repo}}, nil
+}
+
+// GetCommitPage walks the history by committer date, as git log does, and
+// lists each commit's changed files from a diff against its parent
+func (r *goGitRepo) GetCommitPage(page Page, limit int) ([]Commit, Page, error) {
+	if page.End {
+		return nil, page, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if page.Tip == "" {
+		head, err := r.repo.Head()
+		if err != nil {
+			return nil, page, fmt.Errorf("no commits found")
+		}
+		page.Tip = head.Hash().String()
+	}
+
+	iter, err := r.repo.Log(&gogit.LogOptions{From: plumbing.NewHash(page.Tip), Order: gogit.LogOrderCommitterTime})
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to get commits: %w", err)
+	}
+	defer iter.Close()
+
+	var commits []Commit
+	skipped := 0
+	err = iter.ForEach(func(c *object.Commit) error {
+		if c.NumParents() > 1 {
+			return nil // like --no-merges
+		}
+		if skipped < page.Skip {
+			skipped++
+			return nil
+		}
+
+		changes, err := commitChanges(c)
+		if err != nil {
+			return err
+		}
+		var files []string
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" { // deleted
+				name = change.From.Name
+			}
+			files = append(files, name)
+		}
+		slices.Sort(files) // git's order: "a.go" before "a/b.go"
+
+		subject, body := splitMessage(c.Message)
+		commits = append(commits, Commit{
+			Hash:      c.Hash.String()[:7], // Short hash
+			Subject:   subject,
+			Body:      body,
+			Author:    c.Author.Name,
+			Timestamp: c.Author.When.Local(),
+			Ago:       timeAgo(c.Author.When),
+			Files:     files,
+		})
+		if len(commits) == limit {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, page, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	page.Skip += len(commits)
+	page.End = len(commits) < limit
+	return commits, page, nil
+}
+
+// splitMessage splits a commit message the way git's %s and %b do: the
+// subject is the first paragraph joined onto one line
+func splitMessage(message string) (subject, body string) {
+	first, rest, _ := strings.Cut(strings.TrimSpace(message), "\n\n")
+	return strings.Join(nonEmptyLines(first), " "), strings.TrimSpace(rest)
+}
+
+// GetCommitDetail returns the message, changed files and diff of a commit
+func (r *goGitRepo) GetCommitDetail(hash string) (*Detail, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, patch, err := r.commitPatch(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Detail{
+		Hash:    c.Hash.String(),
+		Author:  c.Author.Name,
+		Email:   c.Author.Email,
+		Date:    c.Author.When.Local(),
+		Message: strings.TrimSpace(c.Message),
+	}
+	for _, fp := range patch.FilePatches() {
+		d.Files = append(d.Files, fileChange(fp))
+	}
+	slices.SortFunc(d.Files, func(a, b FileChange) int { return strings.Compare(a.Path, b.Path) })
+	d.setDiff(patch.String())
+	return d, nil
+}
+
+// fileChange counts the lines a file patch adds and deletes
+func fileChange(fp fdiff.FilePatch) FileChange {
+	from, to := fp.Files()
+	f := FileChange{Binary: fp.IsBinary()}
+	if to != nil {
+		f.Path = to.Path()
+	} else if from != nil {
+		f.Path = from.Path()
+	}
+
+	for _, chunk := range fp.Chunks() {
+		content := chunk.Content()
+		lines := strings.Count(content, "\n")
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			lines++
+		}
+		switch chunk.Type() {
+		case fdiff.Add:
+			f.Added += lines
+		case fdiff.Delete:
+			f.Deleted += lines
+		}
+	}
+	return f
+}
+
+// GetCommitHunks returns the diff hunks introduced by a commit
+func (r *goGitRepo) GetCommitHunks(hash string) ([]Hunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, patch, err := r.commitPatch(hash)
+	if err != nil {
+		return nil, err
+	}
+	return parseHunks(patch.String()), nil
+}
+
+// GetCommitStat writes the header, message and diffstat of a commit the way
+// git show --stat does
+func (r *goGitRepo) GetCommitStat(hash string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, patch, err := r.commitPatch(hash)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "commit %s\n", c.Hash)
+	fmt.Fprintf(&b, "Author: %s <%s>\n", c.Author.Name, c.Author.Email)
+	fmt.Fprintf(&b, "Date:   %s\n\n", c.Author.When.Format("Mon Jan 2 15:04:05 2006 -0700"))
+	for _, line := range strings.Split(strings.TrimSpace(c.Message), "\n") {
+		b.WriteString(strings.TrimRight("    "+line, " ") + "\n")
+	}
+	b.WriteString("\n")
+
+	stats := patch.Stats()
+	b.WriteString(stats.String())
+	added, deleted := 0, 0
+	for _, s := range stats {
+		added += s.Addition
+		deleted += s.Deletion
+	}
+	fmt.Fprintf(&b, " %d file(s) changed, %d insertions(+), %d deletions(-)\n", len(stats), added, deleted)
+	return b.String(), nil
+}
+
+// commitPatch resolves a full or abbreviated hash and diffs the commit
+// against its first parent
+func (r *goGitRepo) commitPatch(hash string) (*object.Commit, *object.Patch, error) {
+	h, err := r.repo.ResolveRevision(plumbing.Revision(hash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	c, err := r.repo.CommitObject(*h)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	changes, err := commitChanges(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get diff for %s: %w", hash, err)
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get diff for %s: %w", hash, err)
+	}
+	return c, patch, nil
+}
+
+// commitChanges diffs a commit's tree against its first parent's, or
+// against an empty tree for a root commit
+func commitChanges(c *object.Commit) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	return object.DiffTree(parentTree, tree)
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// The helpers below run from git hooks and while installing them, so git is
+// always there and they use it directly whatever the configured backend.
+
+// HooksDir returns the directory git runs the repository's hooks from,
+// honouring core.hooksPath and linked worktrees
+func HooksDir(dir string) (string, error) {
+	output, err := execRepo{dir: dir}.command("rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+	hooks := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
+
+// TagCommits returns the full hashes of the commits a tag adds since the
+// previous tag, newest first and at most limit of them
+func TagCommits(dir, tag string, limit int) ([]string, error) {
+	r := execRepo{dir: dir}
+	target := "refs/tags/" + tag + "^{commit}"
+
+	rangeSpec := target
+	if prev, err := r.command("describe", "--tags", "--abbrev=0", target+"~1").Output(); err == nil {
+		rangeSpec = "refs/tags/" + strings.TrimSpace(string(prev)) + ".." + target
+	}
+
+	output, err := r.command("log", fmt.Sprintf("-%d", limit), "--no-merges", "--format=%H", rangeSpec).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits of %s: %w", tag, err)
+	}
+	return nonEmptyLines(string(output)), nil
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Repository reads commits from a git repository
+type Repository interface {
+	// GetCommitPage returns up to limit commits starting at the page
+	// cursor, newest first, leaving out merges, and the cursor for the page
+	// after them. An empty page at the end of the history is not an error.
+	GetCommitPage(page Page, limit int) ([]Commit, Page, error)
+	// GetCommitDetail returns the message, changed files and diff of a commit
+	GetCommitDetail(hash string) (*Detail, error)
+	// GetCommitHunks returns the diff hunks introduced by a commit
+	GetCommitHunks(hash string) ([]Hunk, error)
+	// GetCommitStat returns the header, message and diffstat of a commit in
+	// the style of git show --stat
+	GetCommitStat(hash string) (string, error)
+}
+
+// Backend selects how repositories are read
+type Backend string
+
+const (
+	BackendAuto  Backend = ""       // exec when git is installed, else go-git
+	BackendExec  Backend = "exec"   // run the git binary
+	BackendGoGit Backend = "go-git" // read the repository files in-process
+)
+
+// ParseBackend reads a backend name from the config
+func ParseBackend(name string) (Backend, error) {
+	switch b := Backend(name); b {
+	case BackendAuto, BackendExec, BackendGoGit:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown git backend %q - use exec or go-git", name)
+}
+
+// Repo is a git repository commits are read from
+type Repo struct {
+	Name string // label shown next to its commits, the directory's base name
+	Dir  string // top level of the working tree
+	Repository
+}
+
+// Open finds the repository containing dir and reads it with backend
+func Open(dir string, backend Backend) (Repo, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Repo{}, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+
+	if backend == BackendAuto {
+		backend = BackendGoGit
+		if _, err := exec.LookPath("git"); err == nil {
+			backend = BackendExec
+		}
+	}
+	if backend == BackendGoGit {
+		return openGoGit(abs)
+	}
+	return openExec(abs)
+}
+
+// Log is a cursor into the history of one or more repositories, merged
+// newest first. Like Page it is a value: reading returns an advanced copy.
+type Log struct {
+	Repos   []Repo
+	pages   []Page
+	pending [][]Commit // commits read from git but not returned yet, per repo
+}
+
+// NewLog starts reading the merged history of repos from their HEADs
+func NewLog(repos []Repo) Log {
+	return Log{
+		Repos:   repos,
+		pages:   make([]Page, len(repos)),
+		pending: make([][]Commit, len(repos)),
+	}
+}
+
+// End reports whether every commit of every repository has been read
+func (l Log) End() bool {
+	for i := range l.Repos {
+		if !l.pages[i].End || len(l.pending[i]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Next returns up to limit commits across the repositories, newest first,
+// and the cursor for the commits after them. A commit is only returned once
+// every repository has been read back to its date, so a later call never
+// returns anything newer.
+func (l Log) Next(limit int) ([]Commit, Log, error) {
+	pages := slices.Clone(l.pages)
+	pending := slices.Clone(l.pending)
+	for i, repo := range l.Repos {
+		if len(pending[i]) >= limit || pages[i].End {
+			continue
+		}
+		commits, next, err := repo.GetCommitPage(pages[i], limit)
+		if err != nil {
+			if len(l.Repos) > 1 {
+				err = fmt.Errorf("%s: %w", repo.Name, err)
+			}
+			return nil, l, err
+		}
+		for j := range commits {
+			commits[j].Repo = repo
+		}
+		pending[i] = append(slices.Clip(pending[i]), commits...)
+		pages[i] = next
+	}
+
+	// Commits older than the oldest one read from an unfinished repository
+	// may still have newer ones there waiting to be read
+	var frontier time.Time
+	for i := range l.Repos {
+		if !pages[i].End && len(pending[i]) > 0 {
+			if oldest := pending[i][len(pending[i])-1].Timestamp; oldest.After(frontier) {
+				frontier = oldest
+			}
+		}
+	}
+
+	var merged []Commit
+	for len(merged) < limit {
+		newest := -1
+		for i := range pending {
+			if len(pending[i]) > 0 && (newest < 0 || pending[i][0].Timestamp.After(pending[newest][0].Timestamp)) {
+				newest = i
+			}
+		}
+		if newest < 0 || pending[newest][0].Timestamp.Before(frontier) {
+			break
+		}
+		merged = append(merged, pending[newest][0])
+		pending[newest] = pending[newest][1:]
+	}
+
+	return merged, Log{Repos: l.Repos, pages: pages, pending: pending}, nil
+}
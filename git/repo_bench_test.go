@@ -0,0 +1,121 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchCommits is the length of the generated history
+const benchCommits = 200
+
+// benchRepo builds a repository of benchCommits commits in a temp dir, each
+// changing a few lines across a handful of files
+func benchRepo(b *testing.B) string {
+	b.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		b.Skip("git is not installed")
+	}
+	dir := b.TempDir()
+
+	run := func(env []string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			b.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run(nil, "init", "-q")
+	run(nil, "config", "user.name", "Bench")
+	run(nil, "config", "user.email", "bench@example.com")
+	run(nil, "config", "commit.gpgsign", "false")
+
+	for i := range benchCommits {
+		name := filepath.Join(dir, fmt.Sprintf("file%d.go", i%5))
+		f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			b.Fatal(err)
+		}
+		fmt.Fprintf(f, "// change %d\nfunc f%d() int { return %d }\n", i, i, i)
+		f.Close()
+
+		date := fmt.Sprintf("%d +0000", 1700000000+i*3600)
+		run(nil, "add", "-A")
+		run([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, "commit", "-q", "-m", fmt.Sprintf("feat: change %d", i))
+	}
+	return dir
+}
+
+// forBackends runs fn as a sub-benchmark against each backend, with the
+// hash of the newest commit
+func forBackends(b *testing.B, fn func(b *testing.B, repo Repo, hash string)) {
+	dir := benchRepo(b)
+	for _, backend := range []Backend{BackendExec, BackendGoGit} {
+		b.Run(string(backend), func(b *testing.B) {
+			repo, err := Open(dir, backend)
+			if err != nil {
+				b.Fatal(err)
+			}
+			commits, _, err := repo.GetCommitPage(Page{}, 1)
+			if err != nil || len(commits) == 0 {
+				b.Fatalf("failed to read the newest commit: %v", err)
+			}
+			fn(b, repo, commits[0].Hash)
+		})
+	}
+}
+
+func BenchmarkGetCommitPage(b *testing.B) {
+	forBackends(b, func(b *testing.B, repo Repo, _ string) {
+		for b.Loop() {
+			var page Page
+			read := 0
+			for !page.End {
+				commits, next, err := repo.GetCommitPage(page, 50)
+				if err != nil {
+					b.Fatal(err)
+				}
+				read += len(commits)
+				page = next
+			}
+			if read != benchCommits {
+				b.Fatalf("read %d commit(s), want %d", read, benchCommits)
+			}
+		}
+	})
+}
+
+func BenchmarkGetCommitDetail(b *testing.B) {
+	forBackends(b, func(b *testing.B, repo Repo, hash string) {
+		// Skip Repo's cache, which would answer every call after the first
+		for b.Loop() {
+			if _, err := repo.Repository.GetCommitDetail(hash); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetCommitHunks(b *testing.B) {
+	forBackends(b, func(b *testing.B, repo Repo, hash string) {
+		for b.Loop() {
+			if _, err := repo.GetCommitHunks(hash); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetCommitStat(b *testing.B) {
+	forBackends(b, func(b *testing.B, repo Repo, hash string) {
+		for b.Loop() {
+			if _, err := repo.GetCommitStat(hash); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
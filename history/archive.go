@@ -0,0 +1,100 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+)
+
+// archiveTimeLayout is the created_at format used in X data archives
+const archiveTimeLayout = "Mon Jan 02 15:04:05 -0700 2006"
+
+// archiveTweet is the subset of a tweets.js record we care about
+type archiveTweet struct {
+	Tweet struct {
+		ID                string `json:"id_str"`
+		FullText          string `json:"full_text"`
+		CreatedAt         string `json:"created_at"`
+		InReplyToStatusID string `json:"in_reply_to_status_id_str"`
+	} `json:"tweet"`
+}
+
+// ImportArchive reads a tweets.js file from an X data archive and adds
+// the account's own posts to the history. Retweets and replies to other
+// people's posts are skipped; self-replies are kept as threads.
+// Returns the number of new entries added
+func ImportArchive(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	entries, err := parseArchive(data)
+	if err != nil {
+		return 0, err
+	}
+	return Append(entries...)
+}
+
+// parseArchive converts the contents of tweets.js into history entries
+func parseArchive(data []byte) ([]Entry, error) {
+	// tweets.js is a JS assignment: window.YTD.tweets.part0 = [ ... ]
+	start := bytes.IndexByte(data, '[')
+	if start < 0 {
+		return nil, fmt.Errorf("archive does not contain a tweet list")
+	}
+
+	var records []archiveTweet
+	if err := json.Unmarshal(data[start:], &records); err != nil {
+		return nil, fmt.Errorf("failed to parse archive: %w", err)
+	}
+
+	ids := make(map[string]bool, len(records))
+	for _, r := range records {
+		ids[r.Tweet.ID] = true
+	}
+
+	var entries []Entry
+	for _, r := range records {
+		t := r.Tweet
+		if t.ID == "" || strings.HasPrefix(t.FullText, "RT @") {
+			continue
+		}
+		// Keep replies only when they continue one of our own posts
+		if t.InReplyToStatusID != "" && !ids[t.InReplyToStatusID] {
+			continue
+		}
+
+		createdAt, _ := time.Parse(archiveTimeLayout, t.CreatedAt)
+		entries = append(entries, Entry{
+			ID:        t.ID,
+			Text:      html.UnescapeString(t.FullText),
+			ReplyToID: t.InReplyToStatusID,
+			CreatedAt: createdAt,
+			Source:    SourceArchive,
+		})
+	}
+
+	linkThreads(entries)
+	return entries, nil
+}
+
+// linkThreads sets ThreadID on entries by following reply chains to their root
+func linkThreads(entries []Entry) {
+	parent := make(map[string]string, len(entries))
+	for _, e := range entries {
+		parent[e.ID] = e.ReplyToID
+	}
+
+	for i := range entries {
+		root := entries[i].ID
+		for depth := 0; parent[root] != "" && depth < len(entries); depth++ {
+			root = parent[root]
+		}
+		entries[i].ThreadID = root
+	}
+}
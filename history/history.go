@@ -0,0 +1,276 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tomswokowski/shippost/config"
+)
+
+const historyFilePerm = 0600
+
+// Sources of history entries
+const (
+	SourceShippost = "shippost"
+	SourceArchive  = "archive"
+)
+
+// Entry is a single published post
+type Entry struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	ThreadID  string    `json:"thread_id,omitempty"`   // ID of the first post in the thread
+	ReplyToID string    `json:"reply_to_id,omitempty"` // ID of the post this one replies to
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source"`
+}
+
+// historyPath returns the path to the history file
+func historyPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.json"), nil
+}
+
+// Load reads all entries from disk, oldest first
+// Returns an empty slice if no history has been recorded yet
+func Load() ([]Entry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	return entries, nil
+}
+
+// save writes all entries to disk
+func save(entries []Entry) error {
+	if _, err := config.EnsureDir(); err != nil {
+		return err
+	}
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, historyFilePerm); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Append adds entries to the history, skipping IDs that are already recorded
+// Returns the number of entries added
+func Append(newEntries ...Entry) (int, error) {
+	entries, err := Load()
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[e.ID] = true
+	}
+
+	added := 0
+	for _, e := range newEntries {
+		if e.ID == "" || seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		entries = append(entries, e)
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	sortByTime(entries)
+	return added, save(entries)
+}
+
+// Remove deletes entries with the given IDs, e.g. after the posts were
+// deleted on X. Returns the number of entries removed.
+func Remove(ids ...string) (int, error) {
+	entries, err := Load()
+	if err != nil {
+		return 0, err
+	}
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if !remove[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+
+	removed := len(entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, save(kept)
+}
+
+// Record saves a newly published thread. ids and texts are the posts in
+// order; replyToID is the post the first one replied to, if any. When that
+// post is in the history, the new posts join its thread.
+func Record(ids, texts []string, replyToID string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	threadID := ids[0]
+	if replyToID != "" {
+		entries, err := Load()
+		if err != nil {
+			return err
+		}
+		if thread, ok := FindThread(entries, replyToID); ok {
+			threadID = thread.ID
+		}
+	}
+
+	now := time.Now()
+	var newEntries []Entry
+	for i, id := range ids {
+		entry := Entry{
+			ID:        id,
+			ThreadID:  threadID,
+			ReplyToID: replyToID,
+			CreatedAt: now,
+			Source:    SourceShippost,
+		}
+		if i < len(texts) {
+			entry.Text = texts[i]
+		}
+		if i > 0 {
+			entry.ReplyToID = ids[i-1]
+		}
+		newEntries = append(newEntries, entry)
+	}
+
+	_, err := Append(newEntries...)
+	return err
+}
+
+// Thread is a published thread, or a single post, from the history
+type Thread struct {
+	ID    string  // ID of the first post
+	Posts []Entry // oldest first
+}
+
+// Last returns the most recent post of the thread, the one to continue from
+func (t Thread) Last() Entry {
+	return t.Posts[len(t.Posts)-1]
+}
+
+// threadOf returns the ID of the thread an entry belongs to
+func threadOf(e Entry) string {
+	if e.ThreadID != "" {
+		return e.ThreadID
+	}
+	return e.ID
+}
+
+// Threads groups entries into threads, most recently continued first
+func Threads(entries []Entry) []Thread {
+	sorted := append([]Entry(nil), entries...)
+	sortByTime(sorted)
+
+	index := make(map[string]int)
+	var threads []Thread
+	for _, e := range sorted {
+		id := threadOf(e)
+		i, ok := index[id]
+		if !ok {
+			i = len(threads)
+			index[id] = i
+			threads = append(threads, Thread{ID: id})
+		}
+		threads[i].Posts = append(threads[i].Posts, e)
+	}
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Last().CreatedAt.After(threads[j].Last().CreatedAt)
+	})
+	return threads
+}
+
+// FindThread returns the thread containing the post with the given ID
+func FindThread(entries []Entry, id string) (Thread, bool) {
+	for _, e := range entries {
+		if e.ID != id {
+			continue
+		}
+		for _, t := range Threads(entries) {
+			if t.ID == threadOf(e) {
+				return t, true
+			}
+		}
+	}
+	return Thread{}, false
+}
+
+// LastPublished returns the posts shippost published most recently in one go,
+// e.g. a whole thread, oldest first
+func LastPublished(entries []Entry) []Entry {
+	var last []Entry
+	for _, e := range entries {
+		if e.Source != SourceShippost {
+			continue
+		}
+		switch {
+		case len(last) == 0 || e.CreatedAt.After(last[0].CreatedAt):
+			last = []Entry{e}
+		case e.CreatedAt.Equal(last[0].CreatedAt):
+			last = append(last, e)
+		}
+	}
+	return last
+}
+
+// Texts returns the text of every entry, oldest first
+func Texts(entries []Entry) []string {
+	texts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Text != "" {
+			texts = append(texts, e.Text)
+		}
+	}
+	return texts
+}
+
+// sortByTime orders entries oldest first, keeping insertion order for ties
+func sortByTime(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tomswokowski/shippost/config"
+	"github.com/tomswokowski/shippost/hook"
+)
+
+// runHook installs or removes the git hooks that queue shippable commits and
+// tags, and is what those hooks call
+func runHook(args []string) error {
+	fs := flag.NewFlagSet("hook", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage:")
+		fmt.Println("  shippost hook install     Add the post-commit and tag hooks to this repository")
+		fmt.Println("  shippost hook uninstall   Remove them, keeping anything else the hooks run")
+	}
+	fs.Parse(args)
+
+	switch fs.Arg(0) {
+	case "install":
+		written, err := hook.Install(".")
+		for _, path := range written {
+			fmt.Printf("Installed %s\n", path)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("New shippable commits and tags will be offered as a post the next time you launch shippost")
+		return nil
+
+	case "uninstall":
+		changed, err := hook.Uninstall(".")
+		for _, path := range changed {
+			fmt.Printf("Removed shippost from %s\n", path)
+		}
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			fmt.Println("No shippost hooks installed in this repository")
+		}
+		return nil
+
+	case "run":
+		// Called from the hooks themselves; the rules need no credentials
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.Config{}
+		}
+		return hook.Run(fs.Arg(1), os.Stdin, hook.RulesFrom(cfg.Hook))
+	}
+
+	fs.Usage()
+	return fmt.Errorf("expected \"install\" or \"uninstall\"")
+}
@@ -0,0 +1,206 @@
+package hook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomswokowski/shippost/git"
+)
+
+const (
+	beginMarker = "# >>> shippost >>>"
+	endMarker   = "# <<< shippost <<<"
+
+	hookFilePerm = 0755
+)
+
+// hooks are the git hooks shippost adds itself to: post-commit sees new
+// commits, and reference-transaction sees new tags since git has no
+// post-tag hook. The blocks run first, so an exit further down can't skip
+// them; reference-transaction saves its input to a file and hands the rest
+// of the hook a fresh copy of it.
+var hooks = []struct {
+	name string
+	body string
+}{
+	{"post-commit", `if command -v shippost >/dev/null 2>&1; then
+	shippost hook run post-commit || true
+fi`},
+	{"reference-transaction", `if [ "$1" = committed ] && command -v shippost >/dev/null 2>&1 && shippost_input=$(mktemp 2>/dev/null); then
+	cat >"$shippost_input"
+	exec <"$shippost_input"
+	shippost_tags=$(grep ' refs/tags/' "$shippost_input")
+	rm -f "$shippost_input"
+	if [ -n "$shippost_tags" ]; then
+		printf '%s\n' "$shippost_tags" | shippost hook run reference-transaction || true
+	fi
+fi`},
+}
+
+// block is the marked section shippost owns in a hook script
+func block(body string) string {
+	return beginMarker + "\n" +
+		"# Queues shippable commits and tags for a post - remove with \"shippost hook uninstall\"\n" +
+		body + "\n" +
+		endMarker + "\n"
+}
+
+// Install adds shippost's block to the top of the hooks of the repository
+// containing dir. Missing hooks are created; existing ones keep everything
+// they already run, and an older shippost block is replaced. It returns the
+// hook files written.
+func Install(dir string) ([]string, error) {
+	hooksDir, err := git.HooksDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(hooksDir, hookFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	var written []string
+	for _, h := range hooks {
+		path := filepath.Join(hooksDir, h.name)
+		content := "#!/bin/sh\n"
+		perm := os.FileMode(hookFilePerm)
+
+		info, err := os.Stat(path)
+		switch {
+		case err == nil:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return written, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			content = string(data)
+			perm = info.Mode().Perm()
+			if !isShellScript(content) {
+				return written, fmt.Errorf("%s is not a shell script - call \"shippost hook run %s\" from it yourself", path, h.name)
+			}
+			if perm&0111 == 0 {
+				return written, fmt.Errorf("%s isn't executable, so git doesn't run it - make it executable or remove it first", path)
+			}
+		case !os.IsNotExist(err):
+			return written, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		content, _ = removeBlock(content)
+		content = insertBlock(content, block(h.body))
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// Uninstall removes shippost's block from the hooks of the repository
+// containing dir, deleting hooks left with nothing else to run. It returns
+// the hook files changed.
+func Uninstall(dir string) ([]string, error) {
+	hooksDir, err := git.HooksDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, h := range hooks {
+		path := filepath.Join(hooksDir, h.name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return changed, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		content, found := removeBlock(string(data))
+		if !found {
+			continue
+		}
+		if isEmptyScript(content) {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, []byte(content), 0) // the file exists, so its mode is kept
+		}
+		if err != nil {
+			return changed, fmt.Errorf("failed to update %s: %w", path, err)
+		}
+		changed = append(changed, path)
+	}
+	return changed, nil
+}
+
+// insertBlock puts shippost's block right after the #! line of a hook
+// script, followed by a blank line when the script goes on
+func insertBlock(content, block string) string {
+	var shebang string
+	if strings.HasPrefix(content, "#!") {
+		line, rest, _ := strings.Cut(content, "\n")
+		shebang, content = line+"\n", rest
+	}
+	if content == "" {
+		return shebang + block
+	}
+	return shebang + block + "\n" + content
+}
+
+// removeBlock cuts shippost's block out of a hook script, with the blank
+// line after it, or before it for a block older versions appended
+func removeBlock(content string) (string, bool) {
+	start := strings.Index(content, beginMarker)
+	if start < 0 {
+		return content, false
+	}
+	end := strings.Index(content[start:], endMarker)
+	if end < 0 {
+		return content, false
+	}
+	end += start + len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	before, after := content[:start], content[end:]
+	if strings.HasPrefix(after, "\n") {
+		return before + after[1:], true
+	}
+	before = strings.TrimRight(before, "\n")
+	if before != "" {
+		before += "\n"
+	}
+	return before + after, true
+}
+
+// isShellScript reports whether a hook runs with a POSIX-style shell, so a
+// shell block can be added to it. Git runs scripts without a #! line
+// with sh.
+func isShellScript(content string) bool {
+	line, _, _ := strings.Cut(content, "\n")
+	if !strings.HasPrefix(line, "#!") {
+		return true
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return false
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	switch interpreter {
+	case "sh", "bash", "dash", "zsh", "ksh":
+		return true
+	}
+	return false
+}
+
+// isEmptyScript reports whether a hook has nothing left but its #! line
+func isEmptyScript(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#!") {
+			return false
+		}
+	}
+	return true
+}
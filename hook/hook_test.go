@@ -0,0 +1,134 @@
+package hook
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hookRepo creates a git repository and a fake shippost on PATH that logs
+// its arguments and input. It returns the hooks directory and the log path.
+func hookRepo(t *testing.T) (hooksDir, logPath string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+
+	bin := t.TempDir()
+	fake := "#!/bin/sh\n{ echo \"args: $*\"; cat; } >>\"$SHIPPOST_LOG\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "shippost"), []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logPath = filepath.Join(t.TempDir(), "shippost.log")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SHIPPOST_LOG", logPath)
+	return filepath.Join(dir, ".git", "hooks"), logPath
+}
+
+// writeHook writes an existing hook script
+func writeHook(t *testing.T, hooksDir, name, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(hooksDir, name)
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runHook runs a hook script the way git would, returning nothing but
+// failing the test if it errors
+func runHook(t *testing.T, path, stdin string, args ...string) {
+	t.Helper()
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s: %v\n%s", filepath.Base(path), err, out)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestInstallRunsBeforeExit(t *testing.T) {
+	hooksDir, logPath := hookRepo(t)
+	userLog := filepath.Join(t.TempDir(), "user.log")
+	t.Setenv("USER_LOG", userLog)
+	path := writeHook(t, hooksDir, "post-commit", "#!/bin/sh\necho user >>\"$USER_LOG\"\nexit 0\n")
+
+	if _, err := Install(filepath.Dir(hooksDir)); err != nil {
+		t.Fatal(err)
+	}
+	runHook(t, path, "")
+
+	if got := readFile(t, logPath); !strings.Contains(got, "args: hook run post-commit") {
+		t.Errorf("shippost wasn't run before the hook's exit, log: %q", got)
+	}
+	if got := readFile(t, userLog); got != "user\n" {
+		t.Errorf("existing hook ran %q, want it to run once", got)
+	}
+}
+
+func TestInstallKeepsReferenceTransactionInput(t *testing.T) {
+	hooksDir, logPath := hookRepo(t)
+	userLog := filepath.Join(t.TempDir(), "user.log")
+	t.Setenv("USER_LOG", userLog)
+	// An existing hook that reads all of its input
+	path := writeHook(t, hooksDir, "reference-transaction", "#!/bin/sh\ncat >\"$USER_LOG\"\n")
+
+	if _, err := Install(filepath.Dir(hooksDir)); err != nil {
+		t.Fatal(err)
+	}
+	input := "0000000 1111111 refs/tags/v1.0\n0000000 2222222 refs/heads/main\n"
+	runHook(t, path, input, "committed")
+
+	got := readFile(t, logPath)
+	if !strings.Contains(got, "refs/tags/v1.0") || strings.Contains(got, "refs/heads/main") {
+		t.Errorf("shippost got %q, want only the tag update", got)
+	}
+	if got := readFile(t, userLog); got != input {
+		t.Errorf("existing hook read %q, want %q", got, input)
+	}
+}
+
+func TestUninstallRestoresHook(t *testing.T) {
+	hooksDir, _ := hookRepo(t)
+	original := "#!/bin/sh\n\necho user\nexit 0\n"
+	path := writeHook(t, hooksDir, "post-commit", original)
+
+	dir := filepath.Dir(hooksDir)
+	if _, err := Install(dir); err != nil {
+		t.Fatal(err)
+	}
+	// Installing again replaces the block rather than adding a second one
+	if _, err := Install(dir); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(readFile(t, path), beginMarker); n != 1 {
+		t.Fatalf("hook has %d shippost block(s), want 1", n)
+	}
+	if _, err := Uninstall(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, path); got != original {
+		t.Errorf("hook after uninstall:\n%q\nwant:\n%q", got, original)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "reference-transaction")); !os.IsNotExist(err) {
+		t.Error("hook shippost created was left behind")
+	}
+}
@@ -0,0 +1,214 @@
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tomswokowski/shippost/config"
+)
+
+const (
+	queueFilePerm = 0600
+
+	// lockTimeout is how long a change waits for another one to finish, and
+	// staleLockAge is when a lock left by a killed process is taken over
+	lockTimeout  = 5 * time.Second
+	staleLockAge = 30 * time.Second
+)
+
+// Entry is a commit or tag the hooks found worth a post, waiting for the
+// next launch of the TUI
+type Entry struct {
+	Repo    string    `json:"repo"`          // top level of the repository
+	Tag     string    `json:"tag,omitempty"` // set for a new release tag
+	Commits []string  `json:"commits"`       // full hashes, newest first
+	Added   time.Time `json:"added"`
+}
+
+// queuePath returns the path to the hook queue file
+func queuePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hook_queue.json"), nil
+}
+
+// load reads the queue; a missing file is an empty queue
+func load() ([]Entry, error) {
+	path, err := queuePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook queue: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse hook queue: %w", err)
+	}
+	return entries, nil
+}
+
+// save writes the queue through a temporary file, so a hook running at the
+// same time never reads half of it
+func save(entries []Entry) error {
+	dir, err := config.EnsureDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode hook queue: %w", err)
+	}
+
+	f, err := os.CreateTemp(dir, "hook_queue-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to save hook queue: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save hook queue: %w", err)
+	}
+	if err := f.Chmod(queueFilePerm); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save hook queue: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to save hook queue: %w", err)
+	}
+
+	path, err := queuePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to save hook queue: %w", err)
+	}
+	return nil
+}
+
+// lock takes the queue's lock file, so hooks firing together, for a commit
+// and a tag say, don't drop each other's entries. It returns the function
+// that releases it.
+func lock() (func(), error) {
+	dir, err := config.EnsureDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "hook_queue.lock")
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, queueFilePerm)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock hook queue: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock hook queue: %s is held by another process", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// update changes the queue while holding its lock
+func update(change func([]Entry) []Entry) error {
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := load()
+	if err != nil {
+		return err
+	}
+	return save(change(entries))
+}
+
+// Add queues an entry. Commits already queued for the repository, after an
+// amend or rebase for example, are left out, and an entry with nothing new
+// is dropped.
+func Add(e Entry) error {
+	if e.Added.IsZero() {
+		e.Added = time.Now()
+	}
+	return update(func(entries []Entry) []Entry {
+		var fresh []string
+		for _, hash := range e.Commits {
+			if !slices.ContainsFunc(entries, func(q Entry) bool {
+				return q.Repo == e.Repo && slices.Contains(q.Commits, hash)
+			}) {
+				fresh = append(fresh, hash)
+			}
+		}
+		e.Commits = fresh
+		if e.Tag == "" && len(e.Commits) == 0 {
+			return entries
+		}
+		return append(entries, e)
+	})
+}
+
+// Pending returns the queued entries of the given repositories, oldest
+// first. The queue is a convenience, so a broken file reads as empty.
+func Pending(repos []string) []Entry {
+	entries, _ := load()
+	var pending []Entry
+	for _, e := range entries {
+		if slices.Contains(repos, e.Repo) {
+			pending = append(pending, e)
+		}
+	}
+	return pending
+}
+
+// Clear removes the queued entries of the given repositories
+func Clear(repos []string) error {
+	return update(func(entries []Entry) []Entry {
+		return slices.DeleteFunc(entries, func(e Entry) bool {
+			return slices.Contains(repos, e.Repo)
+		})
+	})
+}
+
+// Remove takes commits of a repository off the queue once a post about them
+// is published. Hashes may be abbreviated. Entries left without commits are
+// dropped; other repositories' entries and the rest of the commits stay.
+func Remove(repo string, hashes []string) error {
+	published := func(commit string) bool {
+		return slices.ContainsFunc(hashes, func(h string) bool {
+			return h != "" && strings.HasPrefix(commit, h)
+		})
+	}
+	return update(func(entries []Entry) []Entry {
+		var kept []Entry
+		for _, e := range entries {
+			if e.Repo == repo && len(e.Commits) > 0 {
+				e.Commits = slices.DeleteFunc(slices.Clone(e.Commits), published)
+				if len(e.Commits) == 0 {
+					continue
+				}
+			}
+			kept = append(kept, e)
+		}
+		return kept
+	})
+}
@@ -0,0 +1,60 @@
+package hook
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestAddConcurrent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	const hooks = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, hooks)
+	for i := range hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Add(Entry{Repo: "/repo", Commits: []string{fmt.Sprintf("%040x", i+1)}})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := len(Pending([]string{"/repo"})); got != hooks {
+		t.Errorf("queue has %d entries, want %d", got, hooks)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, e := range []Entry{
+		{Repo: "/api", Commits: []string{"aaaaaaa111", "bbbbbbb222"}},
+		{Repo: "/api", Tag: "v1.0", Commits: []string{"ccccccc333"}},
+		{Repo: "/web", Commits: []string{"aaaaaaa999"}},
+	} {
+		if err := Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A post about one commit of the first entry and the tag's only commit
+	if err := Remove("/api", []string{"aaaaaaa", "ccccccc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	api := Pending([]string{"/api"})
+	if len(api) != 1 || !slices.Equal(api[0].Commits, []string{"bbbbbbb222"}) {
+		t.Errorf("/api queue is %+v, want only the unpublished commit", api)
+	}
+	if web := Pending([]string{"/web"}); len(web) != 1 {
+		t.Errorf("another repository's entry was removed: %+v", web)
+	}
+}
@@ -0,0 +1,113 @@
+package hook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/tomswokowski/shippost/config"
+	"github.com/tomswokowski/shippost/git"
+)
+
+// maxTagCommits caps the commits queued with a release tag
+const maxTagCommits = 20
+
+// Rules decide which commits and tags are worth a post
+type Rules struct {
+	CommitTypes []string // conventional commit types; "*" matches every commit
+	Tags        []string // tag name patterns, as in path.Match
+}
+
+// RulesFrom reads the rules from the config, filling in the defaults
+func RulesFrom(cfg config.HookConfig) Rules {
+	r := Rules{CommitTypes: cfg.CommitTypes, Tags: cfg.Tags}
+	if r.CommitTypes == nil {
+		r.CommitTypes = []string{"feat"}
+	}
+	if r.Tags == nil {
+		r.Tags = []string{"v*"}
+	}
+	return r
+}
+
+// MatchCommit reports whether a commit subject is worth a post
+func (r Rules) MatchCommit(subject string) bool {
+	return slices.Contains(r.CommitTypes, "*") || slices.Contains(r.CommitTypes, git.CommitType(subject))
+}
+
+// MatchTag reports whether a new tag is worth a post
+func (r Rules) MatchTag(tag string) bool {
+	for _, pattern := range r.Tags {
+		if ok, _ := path.Match(pattern, tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Run handles a git hook call from the repository in the working directory,
+// queueing what the rules match. stdin is the hook's standard input.
+func Run(name string, stdin io.Reader, rules Rules) error {
+	repo, err := git.Open(".", git.BackendExec)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "post-commit":
+		return queueHead(repo, rules)
+	case "reference-transaction":
+		return queueTags(repo, stdin, rules)
+	}
+	return fmt.Errorf("unknown hook %q", name)
+}
+
+// queueHead queues the commit just made if the rules match it
+func queueHead(repo git.Repo, rules Rules) error {
+	commits, page, err := repo.GetCommitPage(git.Page{}, 1)
+	if err != nil {
+		return err
+	}
+	// Merge commits are skipped by the log, which then shows an older one
+	if len(commits) == 0 || !strings.HasPrefix(page.Tip, commits[0].Hash) {
+		return nil
+	}
+	if !rules.MatchCommit(commits[0].Subject) {
+		return nil
+	}
+	return Add(Entry{Repo: repo.Dir, Commits: []string{page.Tip}})
+}
+
+// queueTags queues the tags a reference transaction created, each with the
+// commits since the previous tag. Lines on stdin are "<old> <new> <ref>".
+func queueTags(repo git.Repo, stdin io.Reader, rules Rules) error {
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || !isZeroHash(fields[0]) || isZeroHash(fields[1]) {
+			continue // not a new ref
+		}
+		tag, ok := strings.CutPrefix(fields[2], "refs/tags/")
+		if !ok || !rules.MatchTag(tag) {
+			continue
+		}
+
+		commits, err := git.TagCommits(repo.Dir, tag, maxTagCommits)
+		if err != nil {
+			return err
+		}
+		if err := Add(Entry{Repo: repo.Dir, Tag: tag, Commits: commits}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// isZeroHash reports whether a hook's object name is git's all-zero
+// "no object"
+func isZeroHash(hash string) bool {
+	return strings.Trim(hash, "0") == ""
+}
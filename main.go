@@ -4,18 +4,56 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/tomswokowski/shippost/config"
+	"github.com/tomswokowski/shippost/history"
 	"github.com/tomswokowski/shippost/tui"
+	"github.com/tomswokowski/shippost/x"
 )
 
 // version is set by goreleaser at build time
 var version = "dev"
 
+// commands are the headless subcommands, run as "shippost <command> [args]"
+var commands = map[string]func(args []string) error{
+	"post":   runPost,
+	"delete": runDelete,
+	"stats":  runStats,
+	"hook":   runHook,
+}
+
+// newClient creates an X API client for the headless commands. The fake API
+// needs no credentials.
+func newClient() (x.API, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		if !x.FakeEnabled() {
+			return nil, err
+		}
+		cfg = &config.Config{}
+	}
+	return x.New(cfg), nil
+}
+
 func main() {
+	// Handle subcommands before top-level flags
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Define flags
 	setup := flag.Bool("setup", false, "Configure X API credentials")
 	cleanup := flag.Bool("cleanup", false, "Remove stored credentials")
+	importArchive := flag.String("import-archive", "", "Import past posts from an X archive tweets.js file")
+	var repos stringsFlag
+	flag.Var(&repos, "repo", "Read Smart Post commits from this repository (repeat for several)")
 	showVersion := flag.Bool("version", false, "Show version")
 	help := flag.Bool("help", false, "Show help")
 
@@ -41,6 +79,16 @@ func main() {
 		return
 	}
 
+	if *importArchive != "" {
+		added, err := history.ImportArchive(*importArchive)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Imported %d post(s) into your post history\n", added)
+		return
+	}
+
 	if *setup {
 		if err := config.RunSetup(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -49,8 +97,8 @@ func main() {
 		return
 	}
 
-	// Auto-run setup if no config exists
-	if !config.Exists() {
+	// Auto-run setup if no config exists (the fake API needs no credentials)
+	if !config.Exists() && !x.FakeEnabled() {
 		if err := config.RunSetup(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -59,7 +107,7 @@ func main() {
 	}
 
 	// Launch TUI
-	if err := tui.Run(); err != nil {
+	if err := tui.Run(repos); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -70,8 +118,29 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  shippost            Launch the app")
+	fmt.Println("  shippost post       Publish a post without the TUI (see 'shippost post --help')")
+	fmt.Println("  shippost delete     Delete a published post or thread (see 'shippost delete --help')")
+	fmt.Println("  shippost stats      Show engagement of posts published with shippost")
+	fmt.Println("  shippost hook install|uninstall")
+	fmt.Println("                      Offer new feat commits and release tags as posts (see 'shippost hook --help')")
+	fmt.Println("  shippost --repo <path> [--repo <path>...]")
+	fmt.Println("                      Launch with Smart Post reading commits from these repositories")
 	fmt.Println("  shippost --setup    Configure X API credentials")
 	fmt.Println("  shippost --cleanup  Remove stored credentials")
+	fmt.Println("  shippost --import-archive <tweets.js>")
+	fmt.Println("                      Import past posts as AI style examples")
 	fmt.Println("  shippost --version  Show version")
 	fmt.Println("  shippost --help     Show this help")
 }
+
+// stringsFlag is a flag that may be given several times
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
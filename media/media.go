@@ -0,0 +1,239 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// X limits for image uploads
+const (
+	DefaultMaxDimension = 4096
+	DefaultMaxBytes     = 5 * 1024 * 1024
+	MaxGIFBytes         = 15 * 1024 * 1024
+	DefaultJPEGQuality  = 85
+
+	// minJPEGQuality is the lowest quality tried before downscaling further
+	minJPEGQuality = 50
+
+	// minDimension is the smallest size compression will shrink an image to
+	minDimension = 256
+)
+
+// Options controls each preprocessing step
+type Options struct {
+	Resize        bool  // Downscale images larger than MaxDimension
+	MaxDimension  int   // Maximum width or height in pixels
+	Compress      bool  // Recompress images larger than MaxBytes
+	MaxBytes      int64 // Maximum upload size in bytes
+	StripMetadata bool  // Remove EXIF, GPS, XMP and text metadata
+	JPEGQuality   int   // Starting quality when re-encoding JPEGs
+}
+
+// DefaultOptions enables every step with X's limits
+func DefaultOptions() Options {
+	return Options{
+		Resize:        true,
+		MaxDimension:  DefaultMaxDimension,
+		Compress:      true,
+		MaxBytes:      DefaultMaxBytes,
+		StripMetadata: true,
+		JPEGQuality:   DefaultJPEGQuality,
+	}
+}
+
+// Result is an image ready to upload
+type Result struct {
+	Data     []byte
+	MIMEType string
+	Width    int
+	Height   int
+	Changes  []string // human-readable list of what was done, e.g. "resized"
+}
+
+// DetectType returns the MIME type of data based on its contents
+func DetectType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// IsImage reports whether mimeType is an image format X accepts
+func IsImage(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Process detects the real type of an image and applies the enabled steps:
+// metadata stripping, downscaling and recompression to fit the size limit
+func Process(data []byte, opts Options) (*Result, error) {
+	mimeType := DetectType(data)
+	if !IsImage(mimeType) {
+		return nil, fmt.Errorf("unsupported media type: %s", mimeType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	res := &Result{Data: data, MIMEType: mimeType, Width: cfg.Width, Height: cfg.Height}
+
+	// Animated GIFs can't be re-encoded without losing frames - only strip
+	// their metadata blocks and check the size
+	if mimeType == "image/gif" {
+		if opts.StripMetadata {
+			if stripped, removed := stripMetadata(data, mimeType); removed {
+				res.Data = stripped
+				res.Changes = append(res.Changes, "metadata removed")
+			}
+		}
+		if int64(len(res.Data)) > MaxGIFBytes {
+			return nil, fmt.Errorf("GIF is %d MB, X allows at most %d MB", len(res.Data)>>20, MaxGIFBytes>>20)
+		}
+		return res, nil
+	}
+
+	orientation := 1
+	if mimeType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	tooLarge := opts.Resize && opts.MaxDimension > 0 && max(cfg.Width, cfg.Height) > opts.MaxDimension
+	tooBig := opts.Compress && opts.MaxBytes > 0 && int64(len(data)) > opts.MaxBytes
+	// Stripping EXIF drops the orientation tag, so rotated photos must be re-encoded upright
+	mustRotate := opts.StripMetadata && orientation != 1
+	needsEncode := tooLarge || tooBig || mustRotate
+
+	if !needsEncode {
+		if opts.StripMetadata {
+			stripped, removed := stripMetadata(data, mimeType)
+			if removed {
+				res.Data = stripped
+				res.Changes = append(res.Changes, "metadata removed")
+			}
+		}
+		return res, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if orientation != 1 {
+		img = applyOrientation(img, orientation)
+		res.Changes = append(res.Changes, "rotated upright")
+	}
+	if tooLarge {
+		img = fit(img, opts.MaxDimension)
+		res.Changes = append(res.Changes, "resized")
+	}
+
+	// Re-encoding never carries metadata over
+	if err := encode(res, img, opts); err != nil {
+		return nil, err
+	}
+	if opts.StripMetadata {
+		res.Changes = append(res.Changes, "metadata removed")
+	}
+	return res, nil
+}
+
+// encode writes img into res, recompressing and downscaling until it fits
+// opts.MaxBytes when compression is enabled. Transparent images stay PNG;
+// opaque PNGs stay PNG only while that fits, otherwise they become JPEG.
+func encode(res *Result, img image.Image, opts Options) error {
+	quality := opts.JPEGQuality
+	if quality <= 0 || quality > 100 {
+		quality = DefaultJPEGQuality
+	}
+	limit := opts.MaxBytes
+	if !opts.Compress || limit <= 0 {
+		limit = 0
+	}
+	transparent := hasAlpha(img)
+	tryPNG := transparent || res.MIMEType == "image/png"
+	fits := func(buf *bytes.Buffer) bool { return limit == 0 || int64(buf.Len()) <= limit }
+
+	originalType := res.MIMEType
+	compressed := false
+	for {
+		var buf bytes.Buffer
+		mimeType := "image/png"
+		if tryPNG {
+			enc := png.Encoder{CompressionLevel: png.BestCompression}
+			if err := enc.Encode(&buf, img); err != nil {
+				return fmt.Errorf("failed to encode image: %w", err)
+			}
+		}
+		if !tryPNG || (!fits(&buf) && !transparent) {
+			buf.Reset()
+			mimeType = "image/jpeg"
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return fmt.Errorf("failed to encode image: %w", err)
+			}
+		}
+
+		if fits(&buf) {
+			if compressed || mimeType != originalType {
+				res.Changes = append(res.Changes, "compressed")
+			}
+			res.Data = buf.Bytes()
+			res.MIMEType = mimeType
+			res.Width = img.Bounds().Dx()
+			res.Height = img.Bounds().Dy()
+			return nil
+		}
+
+		// Lower the JPEG quality first, then shrink the image
+		b := img.Bounds()
+		compressed = true
+		switch {
+		case mimeType == "image/jpeg" && quality > minJPEGQuality:
+			quality = max(minJPEGQuality, quality-10)
+		case max(b.Dx(), b.Dy()) > minDimension:
+			img = fit(img, max(b.Dx(), b.Dy())*3/4)
+		default:
+			return fmt.Errorf("image is still %s after compression", formatSize(int64(buf.Len())))
+		}
+	}
+}
+
+// fit downscales img so neither side exceeds maxDim
+func fit(img image.Image, maxDim int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if max(w, h) <= maxDim {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*maxDim/w)
+		w = maxDim
+	} else {
+		w = max(1, w*maxDim/h)
+		h = maxDim
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// hasAlpha reports whether any pixel of img is not fully opaque
+func hasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	return true
+}
+
+// formatSize renders a byte count in MB with one decimal
+func formatSize(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
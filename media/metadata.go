@@ -0,0 +1,326 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG, or 1 if it has none
+func jpegOrientation(data []byte) int {
+	for _, seg := range jpegSegments(data) {
+		if seg.marker != 0xE1 || !bytes.HasPrefix(seg.payload, []byte("Exif\x00\x00")) {
+			continue
+		}
+		if o := tiffOrientation(seg.payload[6:]); o >= 1 && o <= 8 {
+			return o
+		}
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag (0x0112) from the first IFD of a TIFF header
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// applyOrientation transforms img so it displays upright for the given EXIF orientation
+func applyOrientation(img image.Image, orientation int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored and rotated 90° counter-clockwise
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // mirrored and rotated 90° clockwise
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			default:
+				return img
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// stripMetadata removes EXIF, XMP, comments and text metadata without
+// re-encoding the image. It reports whether anything was removed.
+func stripMetadata(data []byte, mimeType string) ([]byte, bool) {
+	switch mimeType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	case "image/gif":
+		return stripGIF(data)
+	}
+	return data, false
+}
+
+type jpegSegment struct {
+	marker  byte
+	start   int // offset of the 0xFF marker byte
+	end     int // offset just past the segment
+	payload []byte
+}
+
+// jpegSegments lists the marker segments before the start of scan
+func jpegSegments(data []byte) []jpegSegment {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+	var segs []jpegSegment
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA { // start of scan: image data follows
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		segs = append(segs, jpegSegment{marker: marker, start: pos, end: end, payload: data[pos+4 : end]})
+		pos = end
+	}
+	return segs
+}
+
+// stripJPEG drops APPn and comment segments, keeping JFIF (APP0), the ICC
+// colour profile (APP2) and Adobe colour transform (APP14) so colours render the same
+func stripJPEG(data []byte) ([]byte, bool) {
+	segs := jpegSegments(data)
+	if len(segs) == 0 {
+		return data, false
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	removed := false
+	for _, seg := range segs {
+		isApp := seg.marker >= 0xE0 && seg.marker <= 0xEF
+		keep := !isApp && seg.marker != 0xFE
+		switch {
+		case seg.marker == 0xE0 && bytes.HasPrefix(seg.payload, []byte("JFIF")):
+			keep = true
+		case seg.marker == 0xE2 && bytes.HasPrefix(seg.payload, []byte("ICC_PROFILE")):
+			keep = true
+		case seg.marker == 0xEE && bytes.HasPrefix(seg.payload, []byte("Adobe")):
+			keep = true
+		}
+		if keep {
+			out = append(out, data[seg.start:seg.end]...)
+		} else {
+			removed = true
+		}
+	}
+	if !removed {
+		return data, false
+	}
+	out = append(out, data[segs[len(segs)-1].end:]...)
+	return out, true
+}
+
+// pngMetadataChunks are ancillary chunks that may carry EXIF, text or timestamps
+var pngMetadataChunks = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true}
+
+// stripPNG drops metadata chunks from a PNG
+func stripPNG(data []byte) ([]byte, bool) {
+	const sigLen = 8
+	if len(data) < sigLen {
+		return data, false
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:sigLen]...)
+	removed := false
+	pos := sigLen
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length // length, type, data, CRC
+		if length < 0 || end > len(data) {
+			return data, false
+		}
+		if pngMetadataChunks[string(data[pos+4:pos+8])] {
+			removed = true
+		} else {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	if !removed {
+		return data, false
+	}
+	return out, true
+}
+
+// stripWebP drops EXIF and XMP chunks from an extended WebP and clears their flags
+func stripWebP(data []byte) ([]byte, bool) {
+	const headerLen = 12 // "RIFF", size, "WEBP"
+	if len(data) < headerLen || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return data, false
+	}
+
+	out := make([]byte, headerLen, len(data))
+	copy(out, data[:headerLen])
+	removed := false
+	vp8x := -1
+	pos := headerLen
+	for pos+8 <= len(data) {
+		fourcc := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // chunks are padded to an even size
+		if end > len(data) {
+			return data, false
+		}
+		switch fourcc {
+		case "EXIF", "XMP ":
+			removed = true
+		default:
+			if fourcc == "VP8X" {
+				vp8x = len(out)
+			}
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	if !removed {
+		return data, false
+	}
+
+	// Clear the EXIF (0x08) and XMP (0x04) flags and fix up the RIFF size
+	if vp8x >= 0 && vp8x+8 < len(out) {
+		out[vp8x+8] &^= 0x08 | 0x04
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, true
+}
+
+// gifLoopApps are the application extensions that make an animated GIF loop
+var gifLoopApps = map[string]bool{"NETSCAPE2.0": true, "ANIMEXTS1.0": true}
+
+// gifSubBlocks returns the offset just past the sub-blocks starting at pos,
+// or -1 if they run past the end of data
+func gifSubBlocks(data []byte, pos int) int {
+	for pos < len(data) {
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos
+		}
+		pos += size
+	}
+	return -1
+}
+
+// stripGIF drops comment and application extensions from a GIF, keeping the
+// looping extension so animations still loop. Frames are copied as they are.
+func stripGIF(data []byte) ([]byte, bool) {
+	const headerLen = 13 // signature, version and logical screen descriptor
+	if len(data) < headerLen || !bytes.HasPrefix(data, []byte("GIF")) {
+		return data, false
+	}
+	pos := headerLen
+	if data[10]&0x80 != 0 { // global colour table
+		pos += 3 << (data[10]&0x07 + 1)
+	}
+	if pos > len(data) {
+		return data, false
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:pos]...)
+	removed := false
+	for pos < len(data) {
+		start := pos
+		switch data[pos] {
+		case 0x21: // extension: label, then sub-blocks
+			if pos+2 > len(data) {
+				return data, false
+			}
+			label := data[pos+1]
+			if pos = gifSubBlocks(data, pos+2); pos < 0 {
+				return data, false
+			}
+			app := data[start+2:]
+			isLoop := label == 0xFF && len(app) >= 12 && app[0] == 11 && gifLoopApps[string(app[1:12])]
+			if label == 0xFE || (label == 0xFF && !isLoop) {
+				removed = true
+				continue
+			}
+		case 0x2C: // image descriptor, local colour table, then LZW data
+			if pos+11 > len(data) {
+				return data, false
+			}
+			packed := data[pos+9]
+			pos += 10
+			if packed&0x80 != 0 {
+				pos += 3 << (packed&0x07 + 1)
+			}
+			if pos >= len(data) {
+				return data, false
+			}
+			if pos = gifSubBlocks(data, pos+1); pos < 0 {
+				return data, false
+			}
+		case 0x3B: // trailer
+			pos = len(data)
+		default:
+			return data, false
+		}
+		out = append(out, data[start:pos]...)
+	}
+	if !removed {
+		return data, false
+	}
+	return out, true
+}
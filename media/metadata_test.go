@@ -0,0 +1,76 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// animatedGIF encodes a looping two-frame GIF, then inserts the given blocks
+// after its header the way editors add comments and XMP
+func animatedGIF(t *testing.T, blocks ...[]byte) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{LoopCount: 0}
+	for _, c := range []uint8{0, 1} {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+		frame.Pix[0] = c
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	header := 13
+	if data[10]&0x80 != 0 {
+		header += 3 << (data[10]&0x07 + 1)
+	}
+	out := append([]byte{}, data[:header]...)
+	for _, b := range blocks {
+		out = append(out, b...)
+	}
+	return append(out, data[header:]...)
+}
+
+func TestStripGIF(t *testing.T) {
+	comment := []byte("\x21\xFE\x0Fshot on my desk\x00")
+	xmp := []byte("\x21\xFF\x0BXMP DataXMP\x07<x:xmp>\x00")
+	data := animatedGIF(t, comment, xmp)
+
+	res, err := Process(data, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"shot on my desk", "XMP DataXMP", "<x:xmp>"} {
+		if bytes.Contains(res.Data, []byte(leaked)) {
+			t.Errorf("processed GIF still contains %q", leaked)
+		}
+	}
+	if len(res.Changes) != 1 || res.Changes[0] != "metadata removed" {
+		t.Errorf("changes = %v, want [metadata removed]", res.Changes)
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(res.Data))
+	if err != nil {
+		t.Fatalf("processed GIF doesn't decode: %v", err)
+	}
+	if len(anim.Image) != 2 || anim.LoopCount != 0 {
+		t.Errorf("got %d frame(s) with loop count %d, want 2 looping forever", len(anim.Image), anim.LoopCount)
+	}
+}
+
+func TestStripGIFKeepsPlainGIF(t *testing.T) {
+	data := animatedGIF(t)
+	res, err := Process(data, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res.Data, data) || len(res.Changes) != 0 {
+		t.Errorf("GIF without metadata was changed: %v", res.Changes)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tomswokowski/shippost/history"
+	"github.com/tomswokowski/shippost/x"
+	"golang.org/x/term"
+)
+
+// runPost publishes a post or thread from the command line
+func runPost(args []string) error {
+	fs := flag.NewFlagSet("post", flag.ExitOnError)
+	autoThread := fs.Bool("auto-thread", false, "Split text over the length limit into a thread")
+	numbered := fs.Bool("numbered", false, "Add 1/n counters to an auto-split thread")
+	dryRun := fs.Bool("dry-run", false, "Print the posts instead of publishing them")
+	replyTo := fs.String("reply", "", "Reply to the post with this URL or ID")
+	quote := fs.String("quote", "", "Quote the post with this URL or ID")
+	continueFrom := fs.String("continue", "", "Add to a published thread: a post URL or ID from it, or \"last\" for your latest thread")
+	fs.Usage = func() {
+		fmt.Println("Usage:")
+		fmt.Println("  shippost post [flags] <text>")
+		fmt.Println("  echo <text> | shippost post [flags]")
+		fmt.Println("  shippost post --reply https://x.com/user/status/123 <text>")
+		fmt.Println("  shippost post --continue last <text>")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if countSet(*replyTo, *quote, *continueFrom) > 1 {
+		return fmt.Errorf("use only one of --reply, --quote and --continue")
+	}
+	var replyToID, quoteID, contextText string
+	var err error
+	if *continueFrom != "" {
+		if replyToID, contextText, err = threadTail(*continueFrom); err != nil {
+			return err
+		}
+	}
+	if *replyTo != "" {
+		if replyToID, err = x.ParsePostID(*replyTo); err != nil {
+			return err
+		}
+	}
+	if *quote != "" {
+		if quoteID, err = x.ParsePostID(*quote); err != nil {
+			return err
+		}
+	}
+
+	text, err := readPostText(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	var texts []string
+	if *autoThread {
+		texts = x.SplitThread(text, x.SplitOptions{Numbered: *numbered})
+	} else {
+		if length := x.WeightedLength(text); length > x.MaxPostLength {
+			return fmt.Errorf("post is %d characters (limit %d) - use --auto-thread to split it", length, x.MaxPostLength)
+		}
+		texts = []string{text}
+	}
+
+	if *dryRun {
+		for i, t := range texts {
+			if i > 0 {
+				fmt.Println("---")
+			}
+			fmt.Println(t)
+			fmt.Printf("(%d/%d)\n", x.WeightedLength(t), x.MaxPostLength)
+		}
+		return nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	posts := make([]x.ThreadPost, len(texts))
+	for i, t := range texts {
+		posts[i] = x.ThreadPost{Text: t}
+	}
+	posts[0].ReplyToID = replyToID
+	posts[0].QuotePostID = quoteID
+
+	// Show what is being replied to or quoted, for context
+	if contextText != "" {
+		fmt.Fprintf(os.Stderr, "Continuing thread after: %s\n\n", contextText)
+	} else if id := replyToID + quoteID; id != "" {
+		post, err := client.GetPost(id)
+		if err != nil {
+			return fmt.Errorf("failed to fetch post: %w", err)
+		}
+		verb := "Replying to"
+		if quoteID != "" {
+			verb = "Quoting"
+		}
+		fmt.Fprintf(os.Stderr, "%s @%s: %s\n\n", verb, post.AuthorUsername, post.Text)
+	}
+
+	responses, err := client.PostThread(posts)
+	printPosted(client, responses, texts, replyToID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// threadTail resolves --continue to the post new posts should reply to: the
+// last post of the matching thread in the history, with its text, or the
+// given post itself (without text) when it isn't in the history
+func threadTail(ref string) (id, text string, err error) {
+	entries, err := history.Load()
+	if err != nil {
+		return "", "", err
+	}
+
+	if ref == "last" {
+		threads := history.Threads(entries)
+		if len(threads) == 0 {
+			return "", "", fmt.Errorf("no published posts in your history yet")
+		}
+		last := threads[0].Last()
+		return last.ID, last.Text, nil
+	}
+
+	if id, err = x.ParsePostID(ref); err != nil {
+		return "", "", err
+	}
+	if thread, ok := history.FindThread(entries, id); ok {
+		last := thread.Last()
+		return last.ID, last.Text, nil
+	}
+	return id, "", nil
+}
+
+// countSet returns how many of values are non-empty
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// readPostText takes the post text from the arguments, or from stdin when
+// no arguments are given or the only argument is "-"
+func readPostText(args []string) (string, error) {
+	var text string
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		text = strings.Join(args, " ")
+	} else {
+		if len(args) == 0 && term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", fmt.Errorf("no post text given - pass it as an argument or pipe it to stdin")
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		text = string(data)
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("post text cannot be empty")
+	}
+	return text, nil
+}
+
+// printPosted records published posts in the history and prints their URLs
+func printPosted(client x.API, responses []*x.PostResponse, texts []string, replyToID string) {
+	var ids []string
+	for _, resp := range responses {
+		ids = append(ids, resp.Data.ID)
+		fmt.Println(x.PostURL(resp.Data.ID))
+	}
+	if x.IsFake(client) || len(ids) == 0 {
+		return
+	}
+	if err := history.Record(ids, texts, replyToID); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save post history: %v\n", err)
+	}
+}
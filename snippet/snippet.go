@@ -0,0 +1,365 @@
+package snippet
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultTheme is the syntax highlighting theme used when none is configured
+const DefaultTheme = "dracula"
+
+// Limits keep snippets readable once X scales the image down
+const (
+	MaxLines   = 60
+	MaxColumns = 100
+	tabWidth   = 4
+)
+
+// Layout in pixels. Everything is drawn at 2x so text stays sharp on X.
+const (
+	fontSize     = 26
+	lineSpacing  = 1.45
+	framePadding = 64
+	codePadding  = 36
+	titleBar     = 56
+	cornerRadius = 14
+	gutterGap    = 28
+	shadowOffset = 14
+)
+
+// Options controls how a snippet is rendered
+type Options struct {
+	Filename  string // picks the language and is shown in the title bar
+	Theme     string // chroma style name, e.g. "dracula" or "github"
+	StartLine int    // number of the first line; 0 hides line numbers
+	Diff      bool   // lines are prefixed with ' ', '+' or '-' and tinted accordingly
+}
+
+// Themes returns the available theme names
+func Themes() []string {
+	return styles.Names()
+}
+
+// Render draws code as a PNG with syntax highlighting inside a window frame
+func Render(code string, opts Options) ([]byte, error) {
+	theme := opts.Theme
+	if theme == "" {
+		theme = DefaultTheme
+	}
+	style, ok := styles.Registry[theme]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme: %s", theme)
+	}
+
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(code, "\r\n", "\n"), "\n"), "\n")
+	if len(lines) > MaxLines {
+		return nil, fmt.Errorf("snippet has %d lines, at most %d fit in an image", len(lines), MaxLines)
+	}
+
+	// Split off diff markers so the code itself is highlighted normally
+	markers := make([]byte, len(lines))
+	for i, line := range lines {
+		markers[i] = ' '
+		if opts.Diff && line != "" {
+			markers[i] = line[0]
+			lines[i] = line[1:]
+		}
+		lines[i] = clipLine(strings.ReplaceAll(lines[i], "\t", strings.Repeat(" ", tabWidth)))
+	}
+	lines = dedent(lines)
+
+	tokens, err := highlight(strings.Join(lines, "\n"), opts.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	faces, err := loadFaces()
+	if err != nil {
+		return nil, err
+	}
+	defer faces.close()
+
+	return renderImage(lines, tokens, markers, style, faces, opts)
+}
+
+// highlight tokenises code, one token slice per line
+func highlight(code, filename string) ([][]chroma.Token, error) {
+	lexer := lexers.Match(filename)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to highlight code: %w", err)
+	}
+	return chroma.SplitTokensIntoLines(iterator.Tokens()), nil
+}
+
+// clipLine shortens a line to MaxColumns, marking the cut with an ellipsis
+func clipLine(line string) string {
+	runes := []rune(line)
+	if len(runes) <= MaxColumns {
+		return line
+	}
+	return string(runes[:MaxColumns-1]) + "…"
+}
+
+// dedent removes the indentation shared by every non-blank line
+func dedent(lines []string) []string {
+	common := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if common < 0 || indent < common {
+			common = indent
+		}
+	}
+	if common <= 0 {
+		return lines
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= common {
+			out[i] = line[common:]
+		}
+	}
+	return out
+}
+
+type faceSet struct {
+	regular, bold, italic font.Face
+}
+
+func (f faceSet) close() {
+	f.regular.Close()
+	f.bold.Close()
+	f.italic.Close()
+}
+
+// pick returns the face for a token's style
+func (f faceSet) pick(entry chroma.StyleEntry) font.Face {
+	switch {
+	case entry.Bold == chroma.Yes:
+		return f.bold
+	case entry.Italic == chroma.Yes:
+		return f.italic
+	}
+	return f.regular
+}
+
+// loadFaces parses the bundled Go Mono fonts
+func loadFaces() (faceSet, error) {
+	load := func(ttf []byte) (font.Face, error) {
+		f, err := opentype.Parse(ttf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load font: %w", err)
+		}
+		return opentype.NewFace(f, &opentype.FaceOptions{Size: fontSize, DPI: 72, Hinting: font.HintingFull})
+	}
+
+	var faces faceSet
+	var err error
+	if faces.regular, err = load(gomono.TTF); err != nil {
+		return faces, err
+	}
+	if faces.bold, err = load(gomonobold.TTF); err != nil {
+		return faces, err
+	}
+	if faces.italic, err = load(gomonoitalic.TTF); err != nil {
+		return faces, err
+	}
+	return faces, nil
+}
+
+// renderImage lays out the window and code and encodes the result as PNG
+func renderImage(lines []string, tokens [][]chroma.Token, markers []byte, style *chroma.Style, faces faceSet, opts Options) ([]byte, error) {
+	advance, _ := faces.regular.GlyphAdvance('M')
+	charWidth := advance.Ceil()
+	metrics := faces.regular.Metrics()
+	textHeight := (metrics.Ascent + metrics.Descent).Ceil()
+	lineHeight := int(float64(textHeight) * lineSpacing)
+
+	// The background entry carries the theme's default foreground too
+	base := style.Get(chroma.Background)
+	background := entryColour(base.Background, color.RGBA{0x28, 0x2A, 0x36, 0xFF})
+	fallbackText := color.RGBA{0xF8, 0xF8, 0xF2, 0xFF}
+	if base.Background.IsSet() && base.Background.Brightness() > 0.5 {
+		fallbackText = color.RGBA{0x24, 0x29, 0x2E, 0xFF}
+	}
+	text := entryColour(base.Colour, fallbackText)
+	dim := blend(background, text, 0.45)
+
+	// Gutter wide enough for the largest line number
+	gutter := 0
+	if opts.StartLine > 0 {
+		gutter = len(fmt.Sprint(opts.StartLine+len(lines))) * charWidth
+		gutter += gutterGap
+	}
+	if opts.Diff {
+		gutter += 2 * charWidth
+	}
+
+	columns := 0
+	for _, line := range lines {
+		columns = max(columns, len([]rune(line)))
+	}
+	columns = max(columns, 40)
+
+	winW := 2*codePadding + gutter + columns*charWidth
+	winH := titleBar + 2*codePadding + len(lines)*lineHeight - (lineHeight - textHeight)
+	width := winW + 2*framePadding
+	height := winH + 2*framePadding
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// Themed frame: a diagonal gradient between two accent colours of the theme
+	from := entryColour(style.Get(chroma.Keyword).Colour, color.RGBA{0xFF, 0x79, 0xC6, 0xFF})
+	to := entryColour(style.Get(chroma.NameFunction).Colour, color.RGBA{0x8B, 0xE9, 0xFD, 0xFF})
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := float64(x+y) / float64(width+height)
+			img.SetRGBA(x, y, blend(from, to, t))
+		}
+	}
+
+	win := image.Rect(framePadding, framePadding, framePadding+winW, framePadding+winH)
+	fillRounded(img, win.Add(image.Pt(0, shadowOffset)), cornerRadius, color.RGBA{0, 0, 0, 0x60})
+	fillRounded(img, win, cornerRadius, background)
+
+	// Window buttons and title
+	for i, c := range []color.RGBA{{0xFF, 0x5F, 0x56, 0xFF}, {0xFF, 0xBD, 0x2E, 0xFF}, {0x27, 0xC9, 0x3F, 0xFF}} {
+		center := image.Pt(win.Min.X+codePadding+i*30, win.Min.Y+titleBar/2+4)
+		fillRounded(img, image.Rect(center.X-8, center.Y-8, center.X+8, center.Y+8), 8, c)
+	}
+	baseline := win.Min.Y + titleBar/2 + 4 + (metrics.Ascent.Ceil()-metrics.Descent.Ceil())/2
+	if opts.Filename != "" {
+		titleW := len([]rune(opts.Filename)) * charWidth
+		drawText(img, faces.regular, opts.Filename, win.Min.X+(winW-titleW)/2, baseline, dim)
+	}
+
+	added := blend(background, color.RGBA{0x2E, 0xA0, 0x43, 0xFF}, 0.22)
+	removed := blend(background, color.RGBA{0xF8, 0x51, 0x49, 0xFF}, 0.22)
+
+	lineNo := opts.StartLine
+	top := win.Min.Y + titleBar + codePadding
+	for i := range lines {
+		y := top + i*lineHeight
+		baseline := y + metrics.Ascent.Ceil()
+		x := win.Min.X + codePadding
+
+		// Tint added and removed lines across the full window width
+		band := image.Rect(win.Min.X, y-(lineHeight-textHeight)/2, win.Max.X, y+textHeight+(lineHeight-textHeight+1)/2)
+		switch markers[i] {
+		case '+':
+			draw.Draw(img, band, image.NewUniform(added), image.Point{}, draw.Src)
+		case '-':
+			draw.Draw(img, band, image.NewUniform(removed), image.Point{}, draw.Src)
+		}
+
+		if opts.StartLine > 0 {
+			// Removed lines don't exist in the new file, so they get no number
+			if markers[i] != '-' {
+				num := fmt.Sprint(lineNo)
+				numX := x + gutter - gutterGap - len(num)*charWidth
+				if opts.Diff {
+					numX -= 2 * charWidth
+				}
+				drawText(img, faces.regular, num, numX, baseline, dim)
+				lineNo++
+			}
+		}
+		if opts.Diff && markers[i] != ' ' {
+			drawText(img, faces.regular, string(markers[i]), x+gutter-2*charWidth, baseline, dim)
+		}
+
+		x += gutter
+		if i < len(tokens) {
+			for _, token := range tokens[i] {
+				entry := style.Get(token.Type)
+				value := strings.TrimRight(token.Value, "\n")
+				drawText(img, faces.pick(entry), value, x, baseline, entryColour(entry.Colour, text))
+				x += len([]rune(value)) * charWidth
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func drawText(img *image.RGBA, face font.Face, s string, x, baseline int, c color.Color) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, baseline)}
+	d.DrawString(s)
+}
+
+// entryColour converts a chroma colour, using fallback when the theme leaves it unset
+func entryColour(c chroma.Colour, fallback color.RGBA) color.RGBA {
+	if !c.IsSet() {
+		return fallback
+	}
+	return color.RGBA{c.Red(), c.Green(), c.Blue(), 0xFF}
+}
+
+// blend mixes a towards b by t (0..1)
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xFF}
+}
+
+// fillRounded draws a filled rectangle with rounded corners, blending c over img
+func fillRounded(img *image.RGBA, r image.Rectangle, radius int, c color.RGBA) {
+	draw.DrawMask(img, r, image.NewUniform(c), image.Point{}, roundedMask{r, radius}, r.Min, draw.Over)
+}
+
+// roundedMask is an alpha mask of a rounded rectangle with antialiased corners
+type roundedMask struct {
+	r      image.Rectangle
+	radius int
+}
+
+func (m roundedMask) ColorModel() color.Model { return color.AlphaModel }
+func (m roundedMask) Bounds() image.Rectangle { return m.r }
+
+func (m roundedMask) At(x, y int) color.Color {
+	rad := float64(m.radius)
+	px, py := float64(x)+0.5, float64(y)+0.5
+
+	// Distance into the corner square, if the pixel is in one
+	cx := max(float64(m.r.Min.X)+rad-px, px-(float64(m.r.Max.X)-rad), 0)
+	cy := max(float64(m.r.Min.Y)+rad-py, py-(float64(m.r.Max.Y)-rad), 0)
+	if cx == 0 || cy == 0 {
+		return color.Alpha{0xFF}
+	}
+	d := rad - math.Hypot(cx, cy)
+	switch {
+	case d >= 1:
+		return color.Alpha{0xFF}
+	case d <= 0:
+		return color.Alpha{0}
+	}
+	return color.Alpha{uint8(d * 0xFF)}
+}
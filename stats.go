@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tomswokowski/shippost/stats"
+)
+
+// runStats lists the engagement of posts published with shippost
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	sortBy := fs.String("sort", stats.SortDate, "Sort by date, impressions, likes, reposts or replies")
+	limit := fs.Int("limit", 20, "Show at most this many posts (0 for all)")
+	csvPath := fs.String("csv", "", "Write all rows as CSV to this file, or - for stdout")
+	refresh := fs.Bool("refresh", false, "Fetch fresh metrics instead of using the cache")
+	fs.Usage = func() {
+		fmt.Println("Usage:")
+		fmt.Println("  shippost stats [flags]")
+		fmt.Println()
+		fmt.Println("Flags:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	maxAge := stats.MaxAge
+	if *refresh {
+		maxAge = 0
+	}
+	rows, err := stats.Load(client, maxAge)
+	if err != nil {
+		if len(rows) == 0 {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v (showing cached metrics)\n", err)
+	}
+	if err := stats.Sort(rows, *sortBy); err != nil {
+		return err
+	}
+
+	switch *csvPath {
+	case "":
+	case "-":
+		return stats.WriteCSV(os.Stdout, rows)
+	default:
+		f, err := os.Create(*csvPath)
+		if err != nil {
+			return fmt.Errorf("failed to create CSV file: %w", err)
+		}
+		defer f.Close()
+		if err := stats.WriteCSV(f, rows); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d post(s) to %s\n", len(rows), *csvPath)
+		return nil
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No posts published with shippost yet")
+		return nil
+	}
+	if *limit > 0 && len(rows) > *limit {
+		rows = rows[:*limit]
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tIMPRESSIONS\tLIKES\tREPOSTS\tREPLIES\tPOST")
+	for _, r := range rows {
+		text := strings.Join(strings.Fields(r.Text), " ")
+		if runes := []rune(text); len(runes) > 50 {
+			text = string(runes[:49]) + "…"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n",
+			r.CreatedAt.Local().Format("Jan 2"), r.Impressions, r.Likes, r.Reposts, r.Replies, text)
+	}
+	return tw.Flush()
+}
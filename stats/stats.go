@@ -0,0 +1,222 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tomswokowski/shippost/config"
+	"github.com/tomswokowski/shippost/history"
+	"github.com/tomswokowski/shippost/x"
+)
+
+// MaxAge is how long cached metrics are used before they are fetched again
+const MaxAge = 15 * time.Minute
+
+const cacheFilePerm = 0600
+
+// Sort orders for rows
+const (
+	SortDate        = "date"
+	SortImpressions = "impressions"
+	SortLikes       = "likes"
+	SortReposts     = "reposts"
+	SortReplies     = "replies"
+)
+
+// SortKeys lists the sort orders, in the order the TUI cycles through them
+var SortKeys = []string{SortDate, SortImpressions, SortLikes, SortReposts, SortReplies}
+
+// Row is a published post with its metrics
+type Row struct {
+	history.Entry
+	x.Metrics
+	FetchedAt time.Time
+}
+
+// cached is a post's metrics as stored in the cache file
+type cached struct {
+	x.Metrics
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// cachePath returns the path to the metrics cache
+func cachePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "metrics.json"), nil
+}
+
+// loadCache reads cached metrics by post ID. A missing or broken cache is
+// empty - it is only a cache.
+func loadCache() map[string]cached {
+	cache := make(map[string]cached)
+	path, err := cachePath()
+	if err != nil {
+		return cache
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// saveCache writes cached metrics to disk
+func saveCache(cache map[string]cached) error {
+	if _, err := config.EnsureDir(); err != nil {
+		return err
+	}
+	path, err := cachePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, cacheFilePerm); err != nil {
+		return fmt.Errorf("failed to write metrics cache: %w", err)
+	}
+	return nil
+}
+
+// Load returns the posts published with shippost and their metrics, newest
+// first. Metrics older than maxAge are fetched again; a zero maxAge refreshes
+// everything. When fetching fails, cached metrics are still returned with
+// the error.
+func Load(api x.API, maxAge time.Duration) ([]Row, error) {
+	entries, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []history.Entry
+	for _, e := range entries {
+		if e.Source == history.SourceShippost {
+			posts = append(posts, e)
+		}
+	}
+
+	cache := loadCache()
+	// Posts deleted or dropped from the history have no use in the cache
+	inHistory := make(map[string]bool, len(posts))
+	for _, e := range posts {
+		inHistory[e.ID] = true
+	}
+	changed := false
+	for id := range cache {
+		if !inHistory[id] {
+			delete(cache, id)
+			changed = true
+		}
+	}
+
+	var stale []string
+	for _, e := range posts {
+		if c, ok := cache[e.ID]; !ok || time.Since(c.FetchedAt) >= maxAge {
+			stale = append(stale, e.ID)
+		}
+	}
+
+	var fetchErr error
+	if len(stale) > 0 {
+		metrics, err := api.GetPostMetrics(stale)
+		if err != nil {
+			fetchErr = fmt.Errorf("failed to fetch metrics: %w", err)
+		}
+		now := time.Now()
+		for id, m := range metrics {
+			cache[id] = cached{Metrics: m, FetchedAt: now}
+		}
+		changed = changed || len(metrics) > 0
+	}
+	if changed && !x.IsFake(api) {
+		if err := saveCache(cache); err != nil && fetchErr == nil {
+			fetchErr = err
+		}
+	}
+
+	rows := make([]Row, 0, len(posts))
+	for i := len(posts) - 1; i >= 0; i-- {
+		c, ok := cache[posts[i].ID]
+		if !ok {
+			continue // deleted, or never fetched
+		}
+		rows = append(rows, Row{Entry: posts[i], Metrics: c.Metrics, FetchedAt: c.FetchedAt})
+	}
+	return rows, fetchErr
+}
+
+// Sort orders rows by key, highest first; SortDate puts the newest first
+func Sort(rows []Row, key string) error {
+	var value func(Row) int
+	switch key {
+	case SortDate:
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].CreatedAt.After(rows[j].CreatedAt)
+		})
+		return nil
+	case SortImpressions:
+		value = func(r Row) int { return r.Impressions }
+	case SortLikes:
+		value = func(r Row) int { return r.Likes }
+	case SortReposts:
+		value = func(r Row) int { return r.Reposts }
+	case SortReplies:
+		value = func(r Row) int { return r.Replies }
+	default:
+		return fmt.Errorf("unknown sort %q (use date, impressions, likes, reposts or replies)", key)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return value(rows[i]) > value(rows[j])
+	})
+	return nil
+}
+
+// WriteCSV writes rows as CSV with a header line
+func WriteCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "url", "created_at", "text", "impressions", "likes", "reposts", "replies", "quotes", "bookmarks"})
+	for _, r := range rows {
+		cw.Write([]string{
+			r.ID,
+			x.PostURL(r.ID),
+			r.CreatedAt.Format(time.RFC3339),
+			r.Text,
+			strconv.Itoa(r.Impressions),
+			strconv.Itoa(r.Likes),
+			strconv.Itoa(r.Reposts),
+			strconv.Itoa(r.Replies),
+			strconv.Itoa(r.Quotes),
+			strconv.Itoa(r.Bookmarks),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// ExportCSV writes rows to a new CSV file in dir and returns its path
+func ExportCSV(dir string, rows []Row) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("shippost-stats-%s.csv", time.Now().Format("2006-01-02-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer f.Close()
+	if err := WriteCSV(f, rows); err != nil {
+		return "", err
+	}
+	return path, nil
+}
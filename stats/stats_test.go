@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tomswokowski/shippost/history"
+	"github.com/tomswokowski/shippost/x"
+)
+
+// metricsAPI answers metrics lookups with the same numbers for every post
+type metricsAPI struct {
+	x.API
+}
+
+func (metricsAPI) GetPostMetrics(ids []string) (map[string]x.Metrics, error) {
+	metrics := make(map[string]x.Metrics, len(ids))
+	for _, id := range ids {
+		metrics[id] = x.Metrics{Impressions: 100, Likes: 5}
+	}
+	return metrics, nil
+}
+
+func TestLoadPrunesDeletedPosts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := history.Record([]string{"1001", "1002"}, []string{"first", "second"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(metricsAPI{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(loadCache()); n != 2 {
+		t.Fatalf("cache has %d post(s), want 2", n)
+	}
+
+	if _, err := history.Remove("1001"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := Load(metricsAPI{}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Errorf("got %d row(s), want 1", len(rows))
+	}
+	if _, ok := loadCache()["1001"]; ok {
+		t.Error("deleted post is still in the metrics cache")
+	}
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// imageReader reads an image from the system clipboard
+type imageReader interface {
+	ReadImage() ([]byte, error)
+}
+
+// clipboardReader is the clipboard used by the paste action.
+// Replace it to paste from somewhere other than the system clipboard.
+var clipboardReader imageReader = systemClipboard{}
+
+type clipboardImageMsg struct {
+	post    int
+	version int // threadVersion when the paste started
+	path    string
+	err     error
+}
+
+// systemClipboard reads images using the platform's clipboard tools
+type systemClipboard struct{}
+
+// clipboardCommands are tried in order until one returns image data
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{
+			{"pngpaste", "-"},
+			{"osascript", "-e", "set f to (open for access POSIX file \"/dev/stdout\" with write permission)",
+				"-e", "write (the clipboard as «class PNGf») to f", "-e", "close access f"},
+		}
+	default:
+		return [][]string{
+			{"wl-paste", "--no-newline", "--type", "image/png"},
+			{"xclip", "-selection", "clipboard", "-target", "image/png", "-out"},
+		}
+	}
+}
+
+func (systemClipboard) ReadImage() ([]byte, error) {
+	var tried []string
+	for _, args := range clipboardCommands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		tried = append(tried, args[0])
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err == nil && len(out) > 0 {
+			return out, nil
+		}
+	}
+
+	if len(tried) == 0 {
+		if runtime.GOOS == "darwin" {
+			return nil, fmt.Errorf("no clipboard tool found - install pngpaste")
+		}
+		return nil, fmt.Errorf("no clipboard tool found - install wl-clipboard or xclip")
+	}
+	return nil, fmt.Errorf("clipboard does not contain an image")
+}
+
+// clipboardExts maps detected image types to file extensions
+var clipboardExts = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// pasteImage saves the clipboard image to a temp file and attaches it to post
+func (m Model) pasteImage(post, version int) tea.Cmd {
+	return func() tea.Msg {
+		data, err := clipboardReader.ReadImage()
+		if err != nil {
+			return clipboardImageMsg{post: post, version: version, err: err}
+		}
+
+		ext, ok := clipboardExts[http.DetectContentType(data)]
+		if !ok {
+			return clipboardImageMsg{post: post, version: version, err: fmt.Errorf("clipboard does not contain a supported image")}
+		}
+
+		f, err := os.CreateTemp("", "shippost-paste-*"+ext)
+		if err != nil {
+			return clipboardImageMsg{post: post, version: version, err: fmt.Errorf("failed to save pasted image: %w", err)}
+		}
+		defer f.Close()
+		if _, err := f.Write(data); err != nil {
+			os.Remove(f.Name())
+			return clipboardImageMsg{post: post, version: version, err: fmt.Errorf("failed to save pasted image: %w", err)}
+		}
+
+		return clipboardImageMsg{post: post, version: version, path: f.Name()}
+	}
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeClipboard returns fixed clipboard contents
+type fakeClipboard struct {
+	data []byte
+	err  error
+}
+
+func (c fakeClipboard) ReadImage() ([]byte, error) {
+	return c.data, c.err
+}
+
+// useClipboard swaps the clipboard for the length of a test
+func useClipboard(t *testing.T, c imageReader) {
+	t.Helper()
+	saved := clipboardReader
+	clipboardReader = c
+	t.Cleanup(func() { clipboardReader = saved })
+}
+
+// pasteResult runs the paste action and returns its message
+func pasteResult(t *testing.T) clipboardImageMsg {
+	t.Helper()
+	msg, ok := Model{}.pasteImage(2, 0)().(clipboardImageMsg)
+	if !ok {
+		t.Fatal("paste did not return a clipboardImageMsg")
+	}
+	if msg.post != 2 {
+		t.Errorf("pasted into post %d, want 2", msg.post)
+	}
+	return msg
+}
+
+func TestPasteImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	useClipboard(t, fakeClipboard{data: buf.Bytes()})
+
+	msg := pasteResult(t)
+	if msg.err != nil {
+		t.Fatalf("unexpected error: %v", msg.err)
+	}
+	defer os.Remove(msg.path)
+	if filepath.Ext(msg.path) != ".png" {
+		t.Errorf("saved as %s, want a .png file", msg.path)
+	}
+	saved, err := os.ReadFile(msg.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(saved, buf.Bytes()) {
+		t.Error("saved file differs from the clipboard image")
+	}
+}
+
+func TestPasteTextOnly(t *testing.T) {
+	useClipboard(t, fakeClipboard{data: []byte("just some copied text")})
+
+	msg := pasteResult(t)
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "supported image") {
+		t.Errorf("got error %v, want an unsupported image error", msg.err)
+	}
+	if msg.path != "" {
+		os.Remove(msg.path)
+		t.Errorf("text was saved to %s", msg.path)
+	}
+}
+
+func TestPasteClipboardError(t *testing.T) {
+	want := errors.New("no clipboard tool found")
+	useClipboard(t, fakeClipboard{err: want})
+
+	msg := pasteResult(t)
+	if !errors.Is(msg.err, want) {
+		t.Errorf("got error %v, want %v", msg.err, want)
+	}
+	if msg.path != "" {
+		t.Errorf("got path %s after an error", msg.path)
+	}
+}
@@ -1,140 +1,434 @@
 package tui
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tomswokowski/shippost/ai"
 	"github.com/tomswokowski/shippost/git"
+	"github.com/tomswokowski/shippost/history"
 	"github.com/tomswokowski/shippost/x"
 )
 
 // Message types for async operations
 
 type postResultMsg struct {
+	ids  []string
 	urls []string
 	err  error
 }
 
-type mediaUploadMsg struct {
-	mediaID string
-	path    string
-	err     error
+type deleteResultMsg struct {
+	deleted    int // number of posts deleted, always the last ones
+	err        error
+	historyErr error // the posts were deleted but are still in the history
+}
+
+type uploadProgressMsg struct {
+	index    int
+	state    uploadState
+	err      error
+	progress <-chan uploadProgressMsg
 }
 
 type commitsLoadedMsg struct {
+	load    int  // commitLoad when the commits were asked for
+	more    bool // a later page rather than the first
 	commits []git.Commit
+	next    git.Log // cursor for the commits after them
 	err     error
 }
 
 type aiSuggestionMsg struct {
+	generation  int
 	suggestions []string
 	err         error
 }
 
+type aiProgressMsg struct {
+	generation int
+	update     aiProgress
+	progress   <-chan aiProgress
+}
+
+// aiProgress is the latest state of a running generation: the partial
+// response and, between passes, what Claude is doing
+type aiProgress struct {
+	text   string
+	status string
+}
+
+// commitPageSize is how many commits are read from git at a time
+const commitPageSize = 50
+
+// askPageSize is how many older commits Ask mode reads each time Claude
+// asks for more history
+const askPageSize = 100
+
+// commitPrefetch is how close to the end of the list the cursor gets before
+// the next page is loaded
+const commitPrefetch = 5
+
 // Command functions
 
+// resetCommits forgets the loaded commits so the next load starts again from
+// the newest, and drops any page still on its way
+func (m *Model) resetCommits() {
+	m.commits = nil
+	m.commitLog = git.NewLog(m.repos)
+	m.commitLoad++
+	m.loadingMoreCommits = false
+	m.pickHookCommits = false
+}
+
 func (m Model) loadCommits() tea.Cmd {
+	load, log := m.commitLoad, m.commitLog
 	return func() tea.Msg {
-		commits, err := git.GetRecentCommits(50)
+		commits, next, err := log.Next(commitPageSize)
+		if err == nil && len(commits) == 0 {
+			err = fmt.Errorf("no commits found")
+		}
 		if err != nil {
-			return commitsLoadedMsg{err: err}
+			return commitsLoadedMsg{load: load, err: err}
 		}
-		return commitsLoadedMsg{commits: commits}
+		return commitsLoadedMsg{load: load, commits: commits, next: next}
 	}
 }
 
-func (m Model) generateSuggestion() tea.Cmd {
-	prompt := m.commitPromptInput.Value()
-	allowThread := m.allowThread
+// loadMoreCommits reads the next page of history once the commit cursor
+// nears the end of the list, unless a page is already on its way or the
+// history is exhausted
+func (m *Model) loadMoreCommits() tea.Cmd {
+	if m.loadingMoreCommits || len(m.commits) == 0 || m.commitLog.End() ||
+		m.commitCursor < len(m.filteredCommits)-commitPrefetch {
+		return nil
+	}
+	m.loadingMoreCommits = true
+	load, log := m.commitLoad, m.commitLog
 	return func() tea.Msg {
-		var selectedCommits []git.Commit
-		for _, idx := range m.selectedCommits {
-			if idx < len(m.commits) {
-				selectedCommits = append(selectedCommits, m.commits[idx])
-			}
+		commits, next, err := log.Next(commitPageSize)
+		return commitsLoadedMsg{load: load, more: true, commits: commits, next: next, err: err}
+	}
+}
+
+// handleCommitsLoaded shows the first page of commits, or appends a later
+// page while keeping the cursor on the same commit
+func (m Model) handleCommitsLoaded(msg commitsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.load != m.commitLoad {
+		return m, nil // the commits were reloaded since
+	}
+	if !msg.more {
+		m.status = ""
+		pickHookCommits := m.pickHookCommits
+		m.pickHookCommits = false
+		if msg.err != nil {
+			m.err = msg.err
+			m.state = stateHome
+			return m, nil
+		}
+		m.commits = msg.commits
+		m.commitLog = msg.next
+		m.filterCommits()
+		if pickHookCommits && m.state == stateCommitBrowser {
+			return m.generateFromHookQueue()
+		}
+	} else {
+		m.loadingMoreCommits = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
 		}
+		current := -1
+		if m.commitCursor < len(m.filteredCommits) {
+			current = m.filteredCommits[m.commitCursor]
+		}
+		m.commits = append(m.commits, msg.commits...)
+		m.commitLog = msg.next
+		m.filterCommits()
+		if i := slices.Index(m.filteredCommits, current); i >= 0 {
+			m.commitCursor = i
+		}
+		m.scrollCommits()
+	}
+
+	if m.state != stateCommitBrowser {
+		return m, nil
+	}
+	cmd := m.syncCommitDetail()
+	return m, cmd
+}
+
+// startGeneration switches to the generating view and runs generate in the
+// background with the configured timeout. Partial output is streamed into the
+// view; the run can be cancelled with esc (see handleGeneratingKeys).
+func (m *Model) startGeneration(status string, generate func(ctx context.Context, opts ai.Options) ([]string, error)) tea.Cmd {
+	if m.cancelGeneration != nil {
+		m.cancelGeneration()
+	}
+	m.generation++
+	if m.state != stateGenerating {
+		m.generationReturn = m.state
+	}
+	m.state = stateGenerating
+	m.status = status
+	m.generationPreview = ""
+	m.err = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.AITimeout())
+	m.cancelGeneration = cancel
+
+	generation := m.generation
+	progress := make(chan aiProgress, 1)
+	var latest aiProgress
+	report := func(update func(*aiProgress)) {
+		update(&latest)
+		// Only the latest state matters - replace anything not yet shown
+		select {
+		case <-progress:
+		default:
+		}
+		progress <- latest
+	}
+	opts := ai.Options{
+		PastPosts: m.pastPosts,
+		OnProgress: func(text string) {
+			report(func(p *aiProgress) { p.text = text })
+		},
+		OnStatus: func(status string) {
+			report(func(p *aiProgress) { p.status = status })
+		},
+	}
+
+	run := func() tea.Msg {
+		defer cancel()
+		defer close(progress)
 
 		if !ai.IsClaudeAvailable() {
-			return aiSuggestionMsg{err: fmt.Errorf("claude CLI not found - install Claude Code first")}
+			return aiSuggestionMsg{generation: generation, err: fmt.Errorf("claude CLI not found - install Claude Code first")}
 		}
 
-		suggestions, err := ai.GeneratePostSuggestion(selectedCommits, prompt, allowThread)
+		suggestions, err := generate(ctx, opts)
 		if err != nil {
-			return aiSuggestionMsg{err: err}
+			return aiSuggestionMsg{generation: generation, err: err}
 		}
-		return aiSuggestionMsg{suggestions: suggestions}
+		return aiSuggestionMsg{generation: generation, suggestions: suggestions}
 	}
+
+	return tea.Batch(run, waitForProgress(generation, progress))
 }
 
-func (m Model) generateFromQuery() tea.Cmd {
-	query := m.askQuery
-	commits := m.commits
-	allowThread := m.allowThread
+// waitForProgress delivers the next partial AI response, if any
+func waitForProgress(generation int, progress <-chan aiProgress) tea.Cmd {
 	return func() tea.Msg {
-		if !ai.IsClaudeAvailable() {
-			return aiSuggestionMsg{err: fmt.Errorf("claude CLI not found - install Claude Code first")}
+		update, ok := <-progress
+		if !ok {
+			return nil
 		}
+		return aiProgressMsg{generation: generation, update: update, progress: progress}
+	}
+}
 
-		suggestions, err := ai.GenerateFromQuery(query, commits, allowThread)
-		if err != nil {
-			return aiSuggestionMsg{err: err}
+func (m *Model) generateSuggestion(status string) tea.Cmd {
+	prompt := m.commitPromptInput.Value()
+	allowThread := m.allowThread
+	var selectedCommits []git.Commit
+	for _, idx := range m.selectedCommits {
+		if idx < len(m.commits) {
+			selectedCommits = append(selectedCommits, m.commits[idx])
 		}
-		return aiSuggestionMsg{suggestions: suggestions}
 	}
+	m.draftCommits = selectedCommits
+
+	return m.startGeneration(status, func(ctx context.Context, opts ai.Options) ([]string, error) {
+		return ai.GeneratePostSuggestion(ctx, selectedCommits, prompt, allowThread, opts)
+	})
+}
+
+func (m *Model) generateFromQuery(status string) tea.Cmd {
+	query := m.askQuery
+	commits := m.commits
+	allowThread := m.allowThread
+	m.draftCommits = nil
+
+	// Claude may ask for older history than the browser has loaded; it is
+	// read from where the loaded commits end, on a copy of the cursor
+	log := m.commitLog
+	more := func() ([]git.Commit, error) {
+		older, next, err := log.Next(askPageSize)
+		log = next
+		return older, err
+	}
+
+	return m.startGeneration(status, func(ctx context.Context, opts ai.Options) ([]string, error) {
+		return ai.GenerateFromQuery(ctx, query, commits, more, allowThread, opts)
+	})
 }
 
-func (m Model) uploadMedia(path string) tea.Cmd {
+// waitForUploadProgress delivers the next media upload update, if any
+func waitForUploadProgress(progress <-chan uploadProgressMsg) tea.Cmd {
 	return func() tea.Msg {
-		resp, err := m.xClient.UploadMedia(path)
-		if err != nil {
-			return mediaUploadMsg{err: err}
+		msg, ok := <-progress
+		if !ok {
+			return nil
 		}
-		return mediaUploadMsg{mediaID: resp.MediaIDString, path: path}
+		msg.progress = progress
+		return msg
 	}
 }
 
-func (m Model) doPost() tea.Cmd {
-	return func() tea.Msg {
-		var posts []x.ThreadPost
-		for i, item := range m.thread {
-			text := item.text
-			if i == m.currentPost {
-				text = m.textarea.Value()
-			}
-			if strings.TrimSpace(text) == "" && len(item.mediaIDs) == 0 {
-				continue
+// startPosting uploads every attached file, then publishes the thread once
+// all uploads have succeeded. Upload progress is streamed into m.uploads.
+func (m *Model) startPosting() tea.Cmd {
+	var posts []x.ThreadPost
+	var media [][]string
+	var paths []string
+	index := make(map[string]int) // each file is uploaded once, even if attached twice
+	for i, item := range m.thread {
+		text := item.text
+		if i == m.currentPost {
+			text = m.textarea.Value()
+		}
+		if strings.TrimSpace(text) == "" && len(item.media) == 0 {
+			continue
+		}
+		posts = append(posts, x.ThreadPost{Text: text, Poll: item.poll})
+		media = append(media, item.media)
+		for _, path := range item.media {
+			if _, ok := index[path]; !ok {
+				index[path] = len(paths)
+				paths = append(paths, path)
 			}
-			posts = append(posts, x.ThreadPost{
-				Text:     text,
-				MediaIDs: item.mediaIDs,
-			})
 		}
+	}
 
+	if m.reference != nil && len(posts) > 0 {
+		if m.reference.quote {
+			posts[0].QuotePostID = m.reference.post.ID
+		} else {
+			posts[0].ReplyToID = m.reference.post.ID
+		}
+	}
+
+	m.uploads = make([]upload, len(paths))
+	for i, path := range paths {
+		m.uploads[i] = upload{path: path}
+	}
+	m.status = "Posting..."
+	if len(paths) > 0 {
+		m.status = fmt.Sprintf("Uploading %d file(s)...", len(paths))
+	}
+
+	client := m.xClient
+	progress := make(chan uploadProgressMsg, 2*len(paths))
+
+	run := func() tea.Msg {
 		if len(posts) == 0 {
+			close(progress)
 			return postResultMsg{err: fmt.Errorf("no content to post")}
 		}
 
-		if len(posts) == 1 {
-			resp, err := m.xClient.PostWithOptions(posts[0].Text, &x.PostOptions{
-				MediaIDs: posts[0].MediaIDs,
-			})
+		mediaIDs := make([]string, len(paths))
+		errs := make([]error, len(paths))
+		sem := make(chan struct{}, maxParallelUploads)
+		var wg sync.WaitGroup
+		for i, path := range paths {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				progress <- uploadProgressMsg{index: i, state: uploadRunning}
+				resp, err := client.UploadMedia(path)
+				if err != nil {
+					errs[i] = fmt.Errorf("failed to upload %s: %w", filepath.Base(path), err)
+					progress <- uploadProgressMsg{index: i, state: uploadFailed, err: err}
+					return
+				}
+				mediaIDs[i] = resp.MediaIDString
+				progress <- uploadProgressMsg{index: i, state: uploadDone}
+			}()
+		}
+		wg.Wait()
+		close(progress)
+
+		for _, err := range errs {
 			if err != nil {
 				return postResultMsg{err: err}
 			}
-			return postResultMsg{urls: []string{fmt.Sprintf("https://x.com/i/status/%s", resp.Data.ID)}}
 		}
+		for i := range posts {
+			for _, path := range media[i] {
+				posts[i].MediaIDs = append(posts[i].MediaIDs, mediaIDs[index[path]])
+			}
+		}
+		return publish(client, posts)
+	}
 
-		responses, err := m.xClient.PostThread(posts)
+	return tea.Batch(run, waitForUploadProgress(progress))
+}
+
+// publish posts a single post or a thread and records it in the history
+func publish(client x.API, posts []x.ThreadPost) tea.Msg {
+	if len(posts) == 1 {
+		resp, err := client.PostWithOptions(posts[0].Text, &x.PostOptions{
+			ReplyToID:   posts[0].ReplyToID,
+			QuotePostID: posts[0].QuotePostID,
+			MediaIDs:    posts[0].MediaIDs,
+			Poll:        posts[0].Poll,
+		})
 		if err != nil {
 			return postResultMsg{err: err}
 		}
+		recordHistory(client, posts, []*x.PostResponse{resp})
+		return postResultMsg{ids: []string{resp.Data.ID}, urls: []string{x.PostURL(resp.Data.ID)}}
+	}
 
-		var urls []string
-		for _, resp := range responses {
-			urls = append(urls, fmt.Sprintf("https://x.com/i/status/%s", resp.Data.ID))
+	responses, err := client.PostThread(posts)
+	recordHistory(client, posts, responses)
+	if err != nil {
+		return postResultMsg{err: err}
+	}
+
+	var ids, urls []string
+	for _, resp := range responses {
+		ids = append(ids, resp.Data.ID)
+		urls = append(urls, x.PostURL(resp.Data.ID))
+	}
+	return postResultMsg{ids: ids, urls: urls}
+}
+
+// deletePosts deletes just-published posts, last first, and drops them from
+// the history
+func (m Model) deletePosts(ids []string) tea.Cmd {
+	client := m.xClient
+	return func() tea.Msg {
+		deleted, err := x.DeleteThread(client, ids)
+		msg := deleteResultMsg{deleted: deleted, err: err}
+		if !x.IsFake(client) {
+			if _, err := history.Remove(ids[len(ids)-deleted:]...); err != nil {
+				msg.historyErr = fmt.Errorf("failed to update post history: %w", err)
+			}
 		}
-		return postResultMsg{urls: urls}
+		return msg
+	}
+}
+
+// recordHistory saves published posts to the local post history.
+// Failures are ignored - history is a convenience, not part of posting.
+func recordHistory(client x.API, posts []x.ThreadPost, responses []*x.PostResponse) {
+	if x.IsFake(client) || len(responses) == 0 {
+		return
+	}
+	var ids, texts []string
+	for i, resp := range responses {
+		ids = append(ids, resp.Data.ID)
+		texts = append(texts, posts[i].Text)
 	}
+	history.Record(ids, texts, posts[0].ReplyToID)
 }
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomswokowski/shippost/git"
+)
+
+type commitDetailMsg struct {
+	hash   string
+	detail *git.Detail
+	err    error
+}
+
+// commitDetail is the message, files and diff of the highlighted commit.
+// Wide terminals show it beside the commit list; smaller ones show it in
+// place of the list while it is open.
+type commitDetail struct {
+	open    bool
+	hash    string
+	detail  *git.Detail
+	loading bool
+	err     error
+	scroll  int
+}
+
+// detailShown reports whether the commit browser is showing commit details
+func (m Model) detailShown() bool {
+	return m.wide() || m.commitDetail.open
+}
+
+// syncCommitDetail loads the details of the highlighted commit when they are
+// shown and not loaded yet. The git package caches them, so going back to a
+// commit is instant.
+func (m *Model) syncCommitDetail() tea.Cmd {
+	if !m.detailShown() || m.commitCursor >= len(m.filteredCommits) {
+		return nil
+	}
+	commit := m.commits[m.filteredCommits[m.commitCursor]]
+	hash := commit.Hash
+	if hash == m.commitDetail.hash {
+		return nil
+	}
+	m.commitDetail = commitDetail{open: m.commitDetail.open, hash: hash, loading: true}
+	return func() tea.Msg {
+		detail, err := commit.Repo.GetCommitDetail(hash)
+		return commitDetailMsg{hash: hash, detail: detail, err: err}
+	}
+}
+
+func (m Model) handleCommitDetailLoaded(msg commitDetailMsg) (tea.Model, tea.Cmd) {
+	if msg.hash != m.commitDetail.hash {
+		return m, nil // the cursor has moved on
+	}
+	m.commitDetail.loading = false
+	m.commitDetail.detail = msg.detail
+	m.commitDetail.err = msg.err
+	return m, nil
+}
+
+// detailSize is the width and height available for commit details
+func (m Model) detailSize() (width, height int) {
+	if m.wide() {
+		_, right := m.panes()
+		return right - 4, m.commitBodyHeight() - 2 // box border and padding
+	}
+	return m.contentWidth(), m.commitBodyHeight()
+}
+
+// scrollCommitDetail scrolls the details by delta lines
+func (m *Model) scrollCommitDetail(delta int) {
+	width, height := m.detailSize()
+	bottom := max(0, len(m.commitDetailLines(width))-(height-1)) // last line shows the position
+	m.commitDetail.scroll = max(0, min(bottom, m.commitDetail.scroll+delta))
+}
+
+// commitDetailLines renders the whole of the commit details, one line per
+// element, for scrolling
+func (m Model) commitDetailLines(width int) []string {
+	d := m.commitDetail.detail
+	if d == nil {
+		return nil
+	}
+
+	var lines []string
+	add := func(s string) {
+		lines = append(lines, strings.Split(s, "\n")...)
+	}
+
+	hash := commitHashStyle.Render(d.Hash[:min(7, len(d.Hash))])
+	if len(m.repos) > 1 && m.commitCursor < len(m.filteredCommits) {
+		hash += dimStyle.Render("  " + m.commits[m.filteredCommits[m.commitCursor]].Repo.Name)
+	}
+	if m.commitCursor < len(m.filteredCommits) && slices.Contains(m.selectedCommits, m.filteredCommits[m.commitCursor]) {
+		hash += selectedStyle.Render("  ● selected")
+	}
+	add(hash)
+	add(menuItemStyle.Render(d.Author) + dimStyle.Render(" <"+d.Email+">"))
+	add(dimStyle.Render(d.Date.Local().Format("Mon Jan 2 2006 15:04")))
+	add("")
+
+	subject, body, _ := strings.Cut(d.Message, "\n")
+	add(selectedStyle.Width(width).Render(subject))
+	if body = strings.TrimSpace(body); body != "" {
+		add("")
+		add(menuItemStyle.Width(width).Render(body))
+	}
+	add("")
+
+	added, deleted := d.Stat()
+	add(dimStyle.Render(fmt.Sprintf("%d file(s) changed  ", len(d.Files))) +
+		statusStyle.Render(fmt.Sprintf("+%d", added)) + " " + errorStyle.Render(fmt.Sprintf("-%d", deleted)))
+	for _, f := range d.Files {
+		counts := dimStyle.Render(fmt.Sprintf("%-12s", "binary"))
+		if !f.Binary {
+			counts = statusStyle.Render(fmt.Sprintf("%-6s", fmt.Sprintf("+%d", f.Added))) +
+				errorStyle.Render(fmt.Sprintf("%-6s", fmt.Sprintf("-%d", f.Deleted)))
+		}
+		add(lipgloss.NewStyle().MaxWidth(width).Render("  " + counts + menuItemStyle.Render(f.Path)))
+	}
+
+	if len(d.Diff) > 0 && d.Diff[0] != "" {
+		add("")
+		for _, line := range d.Diff {
+			add(diffLineStyle(line).MaxWidth(width).Render(strings.ReplaceAll(line, "\t", "    ")))
+		}
+		if d.Truncated {
+			add(dimStyle.Render(fmt.Sprintf("… diff cut at %d lines", len(d.Diff))))
+		}
+	}
+	return lines
+}
+
+// diffLineStyle colours a unified diff line by its kind
+func diffLineStyle(line string) lipgloss.Style {
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		return subtitleStyle
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "),
+		strings.HasPrefix(line, "index "), strings.HasPrefix(line, "new file"),
+		strings.HasPrefix(line, "deleted file"), strings.HasPrefix(line, "similarity"),
+		strings.HasPrefix(line, "rename "), strings.HasPrefix(line, "Binary files"):
+		return dimStyle
+	case strings.HasPrefix(line, "@@"):
+		return commitTimeStyle
+	case strings.HasPrefix(line, "+"):
+		return statusStyle
+	case strings.HasPrefix(line, "-"):
+		return errorStyle
+	}
+	return menuItemStyle
+}
+
+// renderCommitDetail shows the visible part of the commit details, with the
+// scroll position on the last line when they don't fit
+func (m Model) renderCommitDetail(width, height int) string {
+	cd := m.commitDetail
+	switch {
+	case cd.loading:
+		return statusStyle.Render("● Loading commit...")
+	case cd.err != nil:
+		return errorStyle.Width(width).Render("✗ " + cd.err.Error())
+	case cd.detail == nil:
+		return dimStyle.Render("No commit highlighted")
+	}
+
+	lines := m.commitDetailLines(width)
+	if len(lines) <= height {
+		return strings.Join(lines, "\n")
+	}
+
+	visible := height - 1
+	scroll := min(cd.scroll, len(lines)-visible)
+	end := scroll + visible
+	position := dimStyle.Render(fmt.Sprintf("── lines %d-%d of %d", scroll+1, end, len(lines)))
+	return strings.Join(lines[scroll:end], "\n") + "\n" + position
+}
@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tomswokowski/shippost/git"
+)
+
+// commitQuery is a parsed commit browser search: free text matched fuzzily
+// against each commit, plus qualifiers like author:sam. Every term and every
+// kind of qualifier must match; a qualifier given several values, as in
+// type:feat,fix or author:sam author:alex, matches any of them.
+type commitQuery struct {
+	terms   []string
+	authors []string
+	files   []string
+	repos   []string
+	types   []string
+	since   time.Time
+}
+
+// parseCommitQuery splits a search like "fix author:sam since:2d" into text
+// and qualifiers. An invalid qualifier is left out of the query and reported.
+func parseCommitQuery(s string, now time.Time) (commitQuery, error) {
+	var q commitQuery
+	var firstErr error
+	for _, word := range strings.Fields(s) {
+		name, value, found := strings.Cut(word, ":")
+		if !found || value == "" {
+			q.terms = append(q.terms, word)
+			continue
+		}
+		values := strings.Split(strings.ToLower(value), ",")
+		switch strings.ToLower(name) {
+		case "author":
+			q.authors = append(q.authors, values...)
+		case "file":
+			q.files = append(q.files, values...)
+		case "repo":
+			q.repos = append(q.repos, values...)
+		case "type":
+			q.types = append(q.types, values...)
+		case "since":
+			since, err := parseSince(value, now)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			q.since = since
+		default:
+			q.terms = append(q.terms, word) // e.g. part of a URL
+		}
+	}
+	return q, firstErr
+}
+
+// parseSince reads an age like 12h, 2d, 3w or 6m (months), or a date like
+// 2025-01-31, as the earliest time a commit may have
+func parseSince(v string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t, nil
+	}
+
+	n, err := strconv.Atoi(v[:len(v)-1])
+	if err == nil && n >= 0 {
+		switch v[len(v)-1] {
+		case 'h':
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		case 'd':
+			return now.AddDate(0, 0, -n), nil
+		case 'w':
+			return now.AddDate(0, 0, -7*n), nil
+		case 'm':
+			return now.AddDate(0, -n, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("since:%s - use an age like 12h, 2d, 3w or 6m, or a date like 2025-01-31", v)
+}
+
+// match scores a commit against the query. Qualifiers only filter, so
+// without text terms every match scores 0. Text terms count most in the
+// subject, whose matched rune positions are returned for highlighting; they
+// may also match the author, a changed file or the body.
+func (q commitQuery) match(c git.Commit) (score int, positions []int, ok bool) {
+	if !q.since.IsZero() && c.Timestamp.Before(q.since) {
+		return 0, nil, false
+	}
+	if len(q.types) > 0 && !slices.Contains(q.types, git.CommitType(c.Subject)) {
+		return 0, nil, false
+	}
+	if _, found := bestFuzzy(q.authors, []string{c.Author}); len(q.authors) > 0 && !found {
+		return 0, nil, false
+	}
+	if _, found := bestFuzzy(q.files, c.Files); len(q.files) > 0 && !found {
+		return 0, nil, false
+	}
+	if _, found := bestFuzzy(q.repos, []string{c.Repo.Name}); len(q.repos) > 0 && !found {
+		return 0, nil, false
+	}
+
+	body := strings.ToLower(c.Body)
+	for _, term := range q.terms {
+		if s, pos, found := subjectMatch(term, c.Subject); found {
+			score += s * 2
+			positions = append(positions, pos...)
+			continue
+		}
+		if s, found := bestFuzzy([]string{term}, append([]string{c.Author}, c.Files...)); found {
+			score += s
+			continue
+		}
+		if strings.Contains(body, strings.ToLower(term)) {
+			score++
+			continue
+		}
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// subjectMatch matches a term against a subject, ranking the term found
+// whole above its letters scattered through the subject
+func subjectMatch(term, subject string) (score int, positions []int, ok bool) {
+	lower := strings.ToLower(subject)
+	i := strings.Index(lower, strings.ToLower(term))
+	if i < 0 || len(lower) != len(subject) {
+		return fuzzyMatch(term, subject)
+	}
+	start := utf8.RuneCountInString(subject[:i])
+	n := utf8.RuneCountInString(term)
+	for p := start; p < start+n; p++ {
+		positions = append(positions, p)
+	}
+	score = 10 * n
+	if start == 0 || subject[i-1] == ' ' {
+		score += 5
+	}
+	return score, positions, true
+}
+
+// bestFuzzy returns the best score of any pattern against any text
+func bestFuzzy(patterns, texts []string) (best int, found bool) {
+	for _, p := range patterns {
+		for _, t := range texts {
+			if s, _, ok := fuzzyMatch(p, t); ok && (!found || s > best) {
+				best, found = s, true
+			}
+		}
+	}
+	return best, found
+}
+
+// filterCommits applies the search to the commits, best text matches first.
+// The sort is stable, so without text terms the commits stay newest first.
+func (m *Model) filterCommits() {
+	q, err := parseCommitQuery(m.commitSearch.Value(), time.Now())
+	m.commitSearchErr = err
+	m.commitHighlights = make(map[int][]int)
+
+	type scored struct {
+		index int
+		score int
+	}
+	var matches []scored
+	for i, c := range m.commits {
+		score, positions, ok := q.match(c)
+		if !ok {
+			continue
+		}
+		matches = append(matches, scored{i, score})
+		if len(positions) > 0 {
+			m.commitHighlights[i] = positions
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	m.filteredCommits = make([]int, len(matches))
+	for i, match := range matches {
+		m.filteredCommits[i] = match.index
+	}
+}
+
+// renderCommitSubject truncates a subject to width and highlights the
+// characters the search matched
+func renderCommitSubject(subject string, width int, positions []int, base func(...string) string) string {
+	shown := truncate(subject, width)
+	if shown != subject {
+		// Matches in the cut-off part would land on the "..."
+		visible := utf8.RuneCountInString(shown) - 3
+		var kept []int
+		for _, p := range positions {
+			if p < visible {
+				kept = append(kept, p)
+			}
+		}
+		positions = kept
+	}
+	return highlightMatches(shown, positions, base, selectedStyle.Underline(true).Render)
+}
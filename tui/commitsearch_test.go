@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"github.com/tomswokowski/shippost/git"
+)
+
+// searchCommits filters commits with a search and returns the subjects left
+func searchCommits(t *testing.T, commits []git.Commit, search string) []string {
+	t.Helper()
+	m := Model{commits: commits, commitSearch: textinput.New()}
+	m.commitSearch.SetValue(search)
+	m.filterCommits()
+	if m.commitSearchErr != nil {
+		t.Fatal(m.commitSearchErr)
+	}
+	var subjects []string
+	for _, i := range m.filteredCommits {
+		subjects = append(subjects, m.commits[i].Subject)
+	}
+	return subjects
+}
+
+func TestFilterCommitsQualifiersKeepNewestFirst(t *testing.T) {
+	now := time.Now()
+	commits := []git.Commit{
+		{Subject: "feat: add themes", Author: "Sasha Amos", Timestamp: now},
+		{Subject: "fix: crash on resize", Author: "Samantha Park", Timestamp: now.Add(-time.Hour)},
+		{Subject: "docs: readme", Author: "Alex Kim", Timestamp: now.Add(-2 * time.Hour)},
+		{Subject: "feat: smart post", Author: "sam", Timestamp: now.Add(-3 * time.Hour)},
+	}
+
+	got := searchCommits(t, commits, "author:sam")
+	want := []string{"feat: add themes", "fix: crash on resize", "feat: smart post"}
+	if !slices.Equal(got, want) {
+		t.Errorf("author:sam gave %q, want newest first %q", got, want)
+	}
+
+	// Text terms still rank the best match first
+	got = searchCommits(t, commits, "author:sam smart")
+	if len(got) == 0 || got[0] != "feat: smart post" {
+		t.Errorf("author:sam smart gave %q, want the smart post first", got)
+	}
+}
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tomswokowski/shippost/history"
+	"github.com/tomswokowski/shippost/x"
+)
+
+// maxVisibleThreads is the number of published threads listed at once
+const maxVisibleThreads = 8
+
+// threadPicker chooses a published thread from the history to continue
+type threadPicker struct {
+	entries []history.Entry
+	threads []history.Thread
+	cursor  int
+	offset  int
+	input   textinput.Model
+	err     error
+}
+
+func newThreadPicker() threadPicker {
+	in := textinput.New()
+	in.Placeholder = "or the URL or ID of any post in the thread"
+	in.Width = 50
+	in.CharLimit = 512
+	return threadPicker{input: in}
+}
+
+// moveCursor moves the highlighted thread, scrolling the visible window
+func (p *threadPicker) moveCursor(delta int) {
+	p.cursor = max(0, min(len(p.threads)-1, p.cursor+delta))
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+maxVisibleThreads {
+		p.offset = p.cursor - maxVisibleThreads + 1
+	}
+}
+
+// openThreadPicker lists the published threads, most recently continued first
+func (m Model) openThreadPicker() (tea.Model, tea.Cmd) {
+	p := &m.threadPicker
+	p.entries, p.err = history.Load()
+	p.threads = history.Threads(p.entries)
+	p.cursor = 0
+	p.offset = 0
+	p.input.SetValue("")
+
+	m.state = stateContinueThread
+	m.isSmartPost = false
+	m.err = nil
+	return m, p.input.Focus()
+}
+
+func (m Model) handleContinueThreadKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.threadPicker
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back):
+		if p.input.Value() != "" {
+			p.input.SetValue("")
+			return m, nil
+		}
+		p.input.Blur()
+		m.state = stateHome
+		m.status = ""
+		m.err = nil
+		return m, nil
+	case key.Matches(msg, k.FilterUp):
+		p.moveCursor(-1)
+		return m, nil
+	case key.Matches(msg, k.FilterDown):
+		p.moveCursor(1)
+		return m, nil
+	case key.Matches(msg, k.PageUp):
+		p.moveCursor(-maxVisibleThreads)
+		return m, nil
+	case key.Matches(msg, k.PageDown):
+		p.moveCursor(maxVisibleThreads)
+		return m, nil
+	case key.Matches(msg, k.Select):
+		if m.status != "" {
+			return m, nil // already fetching
+		}
+		if input := strings.TrimSpace(p.input.Value()); input != "" {
+			id, err := x.ParsePostID(input)
+			if err != nil {
+				p.err = err
+				return m, nil
+			}
+			if thread, ok := history.FindThread(p.entries, id); ok {
+				return m.continueThread(thread.Last())
+			}
+			// Not one of ours (or not in the history) - reply to the post itself
+			p.err = nil
+			m.status = "Fetching post..."
+			return m, m.fetchPost(id)
+		}
+		if p.cursor < len(p.threads) {
+			return m.continueThread(p.threads[p.cursor].Last())
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.err = nil
+	return m, cmd
+}
+
+// continueThread opens compose to chain new posts onto last
+func (m Model) continueThread(last history.Entry) (tea.Model, tea.Cmd) {
+	return m.composeReference(&reference{
+		continuing: true,
+		post:       &x.Post{ID: last.ID, Text: last.Text},
+	})
+}
+
+// composeReference opens Quick Post compose with r as context
+func (m Model) composeReference(r *reference) (tea.Model, tea.Cmd) {
+	m.referenceInput.Blur()
+	m.threadPicker.input.Blur()
+	m.reference = r
+	m.state = stateCompose
+	m.isSmartPost = false
+	m.thread = []threadItem{{text: "", media: nil}}
+	m.threadVersion++
+	m.currentPost = 0
+	m.textarea.SetValue("")
+	m.textarea.Focus()
+	m.err = nil
+	return m, textarea.Blink
+}
+
+func (m Model) viewContinueThread(b *strings.Builder) {
+	p := m.threadPicker
+
+	b.WriteString(subtitleStyle.Render("Continue Thread"))
+	b.WriteString("  ")
+	b.WriteString(dimStyle.Render("add posts to the end of a published thread"))
+	b.WriteString("\n\n")
+
+	if len(p.threads) == 0 {
+		b.WriteString(dimStyle.Render("  No published posts in your history yet"))
+		b.WriteString("\n")
+	} else {
+		if p.offset > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more above", p.offset)))
+			b.WriteString("\n")
+		}
+		end := min(p.offset+maxVisibleThreads, len(p.threads))
+		for i := p.offset; i < end; i++ {
+			thread := p.threads[i]
+			text := truncate(strings.Join(strings.Fields(thread.Posts[0].Text), " "), max(10, min(50, m.contentWidth()-24)))
+			if i == p.cursor {
+				b.WriteString(bulletStyle.Render("▸ "))
+				b.WriteString(selectedStyle.Render(text))
+			} else {
+				b.WriteString("  ")
+				b.WriteString(menuItemStyle.Render(text))
+			}
+			count := "1 post"
+			if n := len(thread.Posts); n > 1 {
+				count = fmt.Sprintf("%d posts", n)
+			}
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  %s · %s", count, thread.Last().CreatedAt.Format("Jan 2"))))
+			b.WriteString("\n")
+		}
+		if remaining := len(p.threads) - end; remaining > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more below", remaining)))
+			b.WriteString("\n")
+		}
+
+		// Show where the new posts will be chained
+		if p.cursor < len(p.threads) {
+			b.WriteString("\n")
+			b.WriteString(dimStyle.Render("Continues after:"))
+			b.WriteString("\n")
+			b.WriteString(boxStyle.Width(m.boxWidth(60)).Render(dimStyle.Render(p.threads[p.cursor].Last().Text)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(activeBoxStyle.Render(p.input.View()))
+	b.WriteString("\n")
+
+	if m.status != "" {
+		b.WriteString(statusStyle.Render("● " + m.status))
+		b.WriteString("\n")
+	}
+	if err := p.err; err != nil || m.err != nil {
+		if err == nil {
+			err = m.err
+		}
+		b.WriteString(errorStyle.Render("✗ " + err.Error()))
+		b.WriteString("\n")
+	}
+
+	k := m.keys
+	b.WriteString(m.renderHelpBar([]helpItem{
+		helpFor("navigate", k.FilterUp, k.FilterDown),
+		helpFor("continue", k.Select),
+		helpFor("back", k.Back),
+	}))
+}
@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tomswokowski/shippost/x"
+)
+
+// threadSeparator splits posts when a thread is edited as a single file
+const threadSeparator = "---"
+
+type editorFinishedMsg struct {
+	path string
+	err  error
+}
+
+// editorCommand returns the user's preferred editor, split into program and args
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// openEditor writes the thread to a temp file and suspends the TUI while
+// the user edits it in $VISUAL/$EDITOR
+func (m Model) openEditor() tea.Cmd {
+	f, err := os.CreateTemp("", "shippost-*.txt")
+	if err != nil {
+		return func() tea.Msg {
+			return editorFinishedMsg{err: fmt.Errorf("failed to create temp file: %w", err)}
+		}
+	}
+	path := f.Name()
+
+	texts := make([]string, len(m.thread))
+	for i, item := range m.thread {
+		texts[i] = item.text
+	}
+	_, err = f.WriteString(formatThreadFile(texts))
+	f.Close()
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg {
+			return editorFinishedMsg{err: fmt.Errorf("failed to write temp file: %w", err)}
+		}
+	}
+
+	args := append(editorCommand(), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{path: path, err: err}
+	})
+}
+
+// applyEditedThread replaces the thread with the posts edited in the file.
+// Media and polls stay attached to the post at the same position. The poll
+// of a post emptied in the editor moves to the post before it, or after it;
+// if neither can take it the edit is refused.
+func (m *Model) applyEditedThread(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read edited thread: %w", err)
+	}
+
+	texts := parseThreadFile(string(data))
+
+	var thread []threadItem
+	var polls []*x.Poll // polls of removed posts
+	var pollAt []int    // how many posts were kept before each of them
+	dropped := 0
+	for i, text := range texts {
+		item := threadItem{text: text}
+		if i < len(m.thread) {
+			old := m.thread[i]
+			item.media = old.media
+			item.poll = old.poll
+			if old.text == text {
+				item.duplicateOf = old.duplicateOf
+			}
+		}
+		// Drop posts emptied in the editor unless they still carry media
+		if strings.TrimSpace(item.text) == "" && len(item.media) == 0 {
+			if item.poll != nil {
+				polls = append(polls, item.poll)
+				pollAt = append(pollAt, len(thread))
+			}
+			continue
+		}
+		thread = append(thread, item)
+	}
+	for i := len(texts); i < len(m.thread); i++ {
+		dropped += len(m.thread[i].media)
+		if m.thread[i].poll != nil {
+			polls = append(polls, m.thread[i].poll)
+			pollAt = append(pollAt, len(thread))
+		}
+	}
+
+	if len(thread) == 0 {
+		thread = []threadItem{{text: "", media: nil}}
+	}
+	for i, poll := range polls {
+		moved := false
+		for _, j := range []int{pollAt[i] - 1, pollAt[i]} {
+			if j >= 0 && j < len(thread) && thread[j].poll == nil && len(thread[j].media) == 0 {
+				thread[j].poll = poll
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			return fmt.Errorf("edit not applied: the posts next to a removed poll already have a poll or media")
+		}
+	}
+
+	m.thread = thread
+	m.threadVersion++
+	if m.currentPost >= len(m.thread) {
+		m.currentPost = len(m.thread) - 1
+	}
+	m.textarea.SetValue(m.thread[m.currentPost].text)
+
+	if dropped > 0 {
+		return fmt.Errorf("%d attachment(s) removed along with their posts", dropped)
+	}
+	return nil
+}
+
+// formatThreadFile joins posts into the editable file format
+func formatThreadFile(texts []string) string {
+	return strings.Join(texts, "\n"+threadSeparator+"\n") + "\n"
+}
+
+// parseThreadFile splits an edited file back into posts on separator lines
+func parseThreadFile(content string) []string {
+	var posts []string
+	var current []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == threadSeparator {
+			posts = append(posts, strings.TrimSpace(strings.Join(current, "\n")))
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	return append(posts, strings.TrimSpace(strings.Join(current, "\n")))
+}
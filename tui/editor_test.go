@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+
+	"github.com/tomswokowski/shippost/x"
+)
+
+// editThread applies an edited thread file to a model holding thread
+func editThread(t *testing.T, thread []threadItem, edited ...string) (Model, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "thread.txt")
+	if err := os.WriteFile(path, []byte(formatThreadFile(edited)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := Model{thread: thread, textarea: textarea.New()}
+	err := m.applyEditedThread(path)
+	return m, err
+}
+
+func TestEditorMovesPollOfEmptiedPost(t *testing.T) {
+	poll := &x.Poll{Options: []string{"Yes", "No"}, DurationMinutes: 60}
+
+	m, err := editThread(t, []threadItem{{text: "one"}, {text: "two", poll: poll}, {text: "three"}}, "one", "", "three")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.thread) != 2 || m.thread[0].poll != poll {
+		t.Errorf("poll wasn't moved to the post before, thread: %+v", m.thread)
+	}
+
+	// The post before has media, so the poll moves to the post after
+	m, err = editThread(t, []threadItem{{text: "one", media: []string{"a.png"}}, {text: "two", poll: poll}, {text: "three"}}, "one", "", "three")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.thread) != 2 || m.thread[1].poll != poll {
+		t.Errorf("poll wasn't moved to the post after, thread: %+v", m.thread)
+	}
+}
+
+func TestEditorRefusesToDropPoll(t *testing.T) {
+	poll := &x.Poll{Options: []string{"Yes", "No"}, DurationMinutes: 60}
+	other := &x.Poll{Options: []string{"Tabs", "Spaces"}, DurationMinutes: 60}
+	thread := []threadItem{{text: "one", poll: other}, {text: "two", poll: poll}, {text: "three", media: []string{"a.png"}}}
+
+	m, err := editThread(t, thread, "one", "", "three")
+	if err == nil {
+		t.Fatal("edit dropping a poll with nowhere to go was applied")
+	}
+	if len(m.thread) != 3 || m.thread[1].poll != poll {
+		t.Errorf("thread changed after a refused edit: %+v", m.thread)
+	}
+}
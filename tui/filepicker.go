@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// supportedMediaExts are the image types that can be attached to a post
+var supportedMediaExts = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true}
+
+// maxVisibleFiles is the number of directory entries shown at once
+const maxVisibleFiles = 10
+
+type fileEntry struct {
+	name  string
+	isDir bool
+}
+
+type fileMatch struct {
+	index     int
+	score     int
+	positions []int
+}
+
+// filePicker browses directories for media to attach. The input fuzzy
+// filters the current directory, or takes a typed/dropped path or glob.
+type filePicker struct {
+	dir      string
+	entries  []fileEntry
+	matches  []fileMatch
+	cursor   int
+	offset   int
+	input    textinput.Model
+	selected []string // absolute paths, in selection order
+	recent   []string
+	limit    int // remaining attachment slots on the post
+	err      error
+}
+
+func newFilePicker() filePicker {
+	in := textinput.New()
+	in.Placeholder = "type to filter, or enter a path like ~/Pictures/*.png"
+	in.Width = 50
+	in.CharLimit = 1024
+	return filePicker{input: in}
+}
+
+// open resets the picker to dir with room for limit more attachments
+func (p *filePicker) open(dir string, limit int, recent []string) {
+	p.selected = nil
+	p.limit = limit
+	p.recent = recent
+	p.input.SetValue("")
+	p.input.Focus()
+	p.chdir(dir)
+}
+
+// chdir lists dir, showing subdirectories and supported media files
+func (p *filePicker) chdir(dir string) {
+	p.err = nil
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		p.err = fmt.Errorf("cannot open %s: %w", abbreviateHome(dir), err)
+		return
+	}
+
+	p.dir = dir
+	p.entries = nil
+	if filepath.Dir(dir) != dir {
+		p.entries = append(p.entries, fileEntry{name: "..", isDir: true})
+	}
+	for _, e := range dirEntries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		if isDir || supportedMediaExts[strings.ToLower(filepath.Ext(name))] {
+			p.entries = append(p.entries, fileEntry{name: name, isDir: isDir})
+		}
+	}
+	sort.SliceStable(p.entries, func(i, j int) bool {
+		if p.entries[i].isDir != p.entries[j].isDir {
+			return p.entries[i].isDir
+		}
+		return strings.ToLower(p.entries[i].name) < strings.ToLower(p.entries[j].name)
+	})
+
+	p.input.SetValue("")
+	p.filter()
+}
+
+// filter fuzzy matches the directory entries against the input
+func (p *filePicker) filter() {
+	p.cursor = 0
+	p.offset = 0
+	p.matches = nil
+
+	query := p.input.Value()
+	if isTypedPath(query) {
+		query = ""
+	}
+	for i, e := range p.entries {
+		if score, positions, ok := fuzzyMatch(query, e.name); ok {
+			p.matches = append(p.matches, fileMatch{index: i, score: score, positions: positions})
+		}
+	}
+	if query != "" {
+		sort.SliceStable(p.matches, func(i, j int) bool {
+			return p.matches[i].score > p.matches[j].score
+		})
+	}
+}
+
+// moveCursor moves the highlighted entry, scrolling the visible window
+func (p *filePicker) moveCursor(delta int) {
+	p.cursor = max(0, min(len(p.matches)-1, p.cursor+delta))
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+maxVisibleFiles {
+		p.offset = p.cursor - maxVisibleFiles + 1
+	}
+}
+
+// current returns the highlighted entry
+func (p *filePicker) current() (fileEntry, bool) {
+	if p.cursor >= len(p.matches) {
+		return fileEntry{}, false
+	}
+	return p.entries[p.matches[p.cursor].index], true
+}
+
+// toggleSelected selects or deselects the highlighted file
+func (p *filePicker) toggleSelected() {
+	entry, ok := p.current()
+	if !ok || entry.isDir {
+		return
+	}
+	path := filepath.Join(p.dir, entry.name)
+	for i, s := range p.selected {
+		if s == path {
+			p.selected = append(p.selected[:i], p.selected[i+1:]...)
+			return
+		}
+	}
+	if len(p.selected) >= p.limit {
+		p.err = fmt.Errorf("only %d more image(s) fit in this post", p.limit)
+		return
+	}
+	p.selected = append(p.selected, path)
+	p.err = nil
+}
+
+// isSelected reports whether the file at path is selected
+func (p *filePicker) isSelected(path string) bool {
+	for _, s := range p.selected {
+		if s == path {
+			return true
+		}
+	}
+	return false
+}
+
+// isTypedPath reports whether input looks like a path rather than a filter
+func isTypedPath(input string) bool {
+	input = strings.Trim(strings.TrimSpace(input), `'"`)
+	return strings.HasPrefix(input, "/") || strings.HasPrefix(input, "~") ||
+		strings.HasPrefix(input, ".") || strings.ContainsAny(input, `/\*?[`)
+}
+
+// resolveTypedPath cleans up a typed or drag-and-dropped path, expands ~
+// and globs, and returns the matching files relative to dir
+func resolveTypedPath(input, dir string) ([]string, error) {
+	// Clean up paths from drag-and-drop (escaped spaces, quotes)
+	path := strings.TrimSpace(input)
+	path = strings.ReplaceAll(path, "\\ ", " ")
+	path = strings.Trim(path, "'\"")
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no such file: %s", abbreviateHome(path))
+	}
+	return matches, nil
+}
+
+// abbreviateHome replaces the home directory prefix of path with ~
+func abbreviateHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
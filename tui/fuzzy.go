@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// fuzzyMatch reports whether all characters of pattern appear in text in
+// order (case-insensitive). The score favours consecutive matches and
+// matches at word starts; positions are the rune indexes of matched
+// characters, for highlighting.
+func fuzzyMatch(pattern, text string) (score int, positions []int, ok bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+
+	pat := []rune(strings.ToLower(pattern))
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Lowercasing changed the length (rare Unicode cases) - match on the original
+		lower = runes
+	}
+
+	p := 0
+	prev := -2
+	for i := 0; i < len(lower) && p < len(pat); i++ {
+		if lower[i] != pat[p] {
+			continue
+		}
+
+		score++
+		if i == prev+1 {
+			score += 5 // consecutive
+		}
+		if i == 0 || isWordBoundary(runes[i-1], runes[i]) {
+			score += 3 // start of a word
+		}
+		positions = append(positions, i)
+		prev = i
+		p++
+	}
+
+	if p < len(pat) {
+		return 0, nil, false
+	}
+
+	// Prefer shorter texts and earlier first matches
+	score -= positions[0] / 4
+	score -= utf8.RuneCountInString(text) / 32
+	return score, positions, true
+}
+
+// isWordBoundary reports whether cur starts a new word after prev
+func isWordBoundary(prev, cur rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// highlightMatches renders text with the runes at positions styled as matches
+func highlightMatches(text string, positions []int, base, match func(...string) string) string {
+	if len(positions) == 0 {
+		return base(text)
+	}
+
+	hit := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		hit[p] = true
+	}
+
+	var b strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			b.WriteString(match(string(run)))
+		} else {
+			b.WriteString(base(string(run)))
+		}
+		run = run[:0]
+	}
+
+	for i, r := range []rune(text) {
+		if hit[i] != runMatched {
+			flush()
+			runMatched = hit[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tomswokowski/shippost/hook"
+)
+
+// repoDirs returns the top level directories of the Smart Post repositories
+func (m Model) repoDirs() []string {
+	dirs := make([]string, len(m.repos))
+	for i, r := range m.repos {
+		dirs[i] = r.Dir
+	}
+	return dirs
+}
+
+// hookPromptText describes what the git hooks queued since the last launch
+func (m Model) hookPromptText() string {
+	var tags []string
+	commits := 0
+	for _, e := range m.hookQueue {
+		if e.Tag != "" {
+			tags = append(tags, e.Tag)
+		}
+		commits += len(e.Commits)
+	}
+	if len(tags) > 0 {
+		return fmt.Sprintf("New tag %s (%d commit(s)) — draft a post?", strings.Join(tags, ", "), commits)
+	}
+	return fmt.Sprintf("%d new shippable commit(s) — draft a post?", commits)
+}
+
+// handleHookPromptKeys answers the home screen prompt about queued commits:
+// confirming drafts a post about them, declining forgets them and any other
+// key asks again next launch. A draft keeps the queue until it is published.
+func (m Model) handleHookPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.keys
+	m.hookPrompt = false
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Confirm):
+		m.reference = nil
+		m.isSmartPost = true
+		m.openCommitBrowser()
+		m.pickHookCommits = true
+		var tags []string
+		for _, e := range m.hookQueue {
+			if e.Tag != "" {
+				tags = append(tags, e.Tag)
+			}
+		}
+		if len(tags) > 0 {
+			m.commitPromptInput.SetValue("Announce the release of " + strings.Join(tags, ", "))
+		}
+		return m, m.loadCommits()
+	case key.Matches(msg, k.Decline):
+		if err := hook.Clear(m.repoDirs()); err != nil {
+			m.err = err
+		}
+	}
+	return m, nil
+}
+
+// forgetPublishedCommits takes the commits a published Smart Post was
+// written from off the git hook queue
+func (m Model) forgetPublishedCommits() error {
+	if !m.isSmartPost {
+		return nil
+	}
+	byRepo := make(map[string][]string)
+	for _, c := range m.draftCommits {
+		byRepo[c.Repo.Dir] = append(byRepo[c.Repo.Dir], c.Hash)
+	}
+	var errs []error
+	for repo, hashes := range byRepo {
+		errs = append(errs, hook.Remove(repo, hashes))
+	}
+	return errors.Join(errs...)
+}
+
+// generateFromHookQueue selects the loaded commits the git hooks queued and
+// writes a post about them
+func (m Model) generateFromHookQueue() (tea.Model, tea.Cmd) {
+	m.selectedCommits = nil
+	for _, e := range m.hookQueue {
+		for _, hash := range e.Commits {
+			for i, c := range m.commits {
+				if c.Repo.Dir == e.Repo && strings.HasPrefix(hash, c.Hash) {
+					m.selectedCommits = append(m.selectedCommits, i)
+					break
+				}
+			}
+		}
+	}
+	m.hookQueue = nil
+	if len(m.selectedCommits) == 0 {
+		m.err = fmt.Errorf("the queued commits are no longer in the history")
+		cmd := m.syncCommitDetail()
+		return m, cmd
+	}
+	return m.generateFromCommits()
+}
@@ -0,0 +1,367 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/tomswokowski/shippost/config"
+)
+
+// keyMap holds every rebindable action. Defaults come from defaultKeyMap and
+// can be overridden in ~/.config/shippost/keys.json.
+type keyMap struct {
+	// General
+	Help    key.Binding
+	Back    key.Binding
+	Close   key.Binding
+	Quit    key.Binding
+	Confirm key.Binding
+	Decline key.Binding
+
+	// Menus and lists
+	Up       key.Binding
+	Down     key.Binding
+	Select   key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+
+	// Commit browser
+	ToggleCommit key.Binding
+	SelectAll    key.Binding
+	Search       key.Binding
+	FocusPrompt  key.Binding
+	ToggleThread key.Binding
+	Generate     key.Binding
+	Details      key.Binding
+	ScrollUp     key.Binding
+	ScrollDown   key.Binding
+
+	// Compose
+	Send          key.Binding
+	Regenerate    key.Binding
+	AttachFile    key.Binding
+	PasteImage    key.Binding
+	CodeImage     key.Binding
+	Poll          key.Binding
+	Editor        key.Binding
+	Split         key.Binding
+	SplitNumbered key.Binding
+	InsertAfter   key.Binding
+	InsertBefore  key.Binding
+	DeletePost    key.Binding
+	Duplicate     key.Binding
+	Merge         key.Binding
+	MoveUp        key.Binding
+	MoveDown      key.Binding
+	Outline       key.Binding
+	RemoveMedia   key.Binding
+	PrevPost      key.Binding
+	NextPost      key.Binding
+
+	// Thread outline, on top of the compose thread keys
+	OutlineAdd       key.Binding
+	OutlineAddBefore key.Binding
+	OutlineDuplicate key.Binding
+	OutlineMerge     key.Binding
+	OutlineDelete    key.Binding
+	OutlineMoveUp    key.Binding
+	OutlineMoveDown  key.Binding
+
+	// File, code and thread pickers, where letters type into the filter
+	FilterUp    key.Binding
+	FilterDown  key.Binding
+	ToggleFile  key.Binding
+	ParentDir   key.Binding
+	RecentDir   key.Binding
+	ToggleQuote key.Binding
+
+	// Poll editor
+	NextField   key.Binding
+	PrevField   key.Binding
+	ShorterPoll key.Binding
+	LongerPoll  key.Binding
+	RemovePoll  key.Binding
+
+	// Stats and after posting
+	SortStats    key.Binding
+	RefreshStats key.Binding
+	ExportStats  key.Binding
+	Undo         key.Binding
+	NewPost      key.Binding
+}
+
+// Titles of the keymap sections, as the full help shows them
+const (
+	sectionGeneral = "General"
+	sectionLists   = "Menus and lists"
+	sectionCommits = "Commit browser"
+	sectionCompose = "Compose"
+	sectionOutline = "Thread outline"
+	sectionPickers = "Pickers"
+	sectionPoll    = "Poll editor"
+	sectionPosts   = "Stats and posted"
+)
+
+// keyAction is a named binding, as it appears in keys.json
+type keyAction struct {
+	name    string
+	binding *key.Binding
+}
+
+// keySection is a group of actions in the full help
+type keySection struct {
+	title   string
+	actions []keyAction
+}
+
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), desc))
+}
+
+func defaultKeyMap() keyMap {
+	k := keyMap{
+		Help:    binding("show all keys", "?", "f1"),
+		Back:    binding("back", "esc"),
+		Close:   binding("back/quit from lists", "q"),
+		Quit:    binding("quit", "ctrl+c"),
+		Confirm: binding("yes", "y"),
+		Decline: binding("no", "n"),
+
+		Up:       binding("up", "up", "k"),
+		Down:     binding("down", "down", "j"),
+		Select:   binding("select", "enter"),
+		PageUp:   binding("page up", "pgup"),
+		PageDown: binding("page down", "pgdown"),
+
+		ToggleCommit: binding("select commit", " "),
+		SelectAll:    binding("select all", "a"),
+		Search:       binding("search", "/"),
+		FocusPrompt:  binding("prompt", "tab"),
+		ToggleThread: binding("single/thread", "ctrl+t"),
+		Generate:     binding("generate", "ctrl+g", "ctrl+enter"),
+		Details:      binding("commit details", "d"),
+		ScrollUp:     binding("scroll details up", "pgup", "shift+up"),
+		ScrollDown:   binding("scroll details down", "pgdown", "shift+down"),
+
+		Send:          binding("send", "ctrl+s"),
+		Regenerate:    binding("regenerate", "ctrl+r"),
+		AttachFile:    binding("attach", "ctrl+o"),
+		PasteImage:    binding("paste image", "alt+v"),
+		CodeImage:     binding("code image", "alt+c"),
+		Poll:          binding("poll", "alt+p"),
+		Editor:        binding("editor", "ctrl+e"),
+		Split:         binding("split into thread", "ctrl+t"),
+		SplitNumbered: binding("split with 1/n", "alt+t"),
+		InsertAfter:   binding("add post", "ctrl+n"),
+		InsertBefore:  binding("add post before", "alt+n"),
+		DeletePost:    binding("delete post", "ctrl+d"),
+		Duplicate:     binding("duplicate post", "alt+d"),
+		Merge:         binding("merge with next", "alt+m"),
+		MoveUp:        binding("move post up", "alt+up", "alt+k"),
+		MoveDown:      binding("move post down", "alt+down", "alt+j"),
+		Outline:       binding("outline", "ctrl+l"),
+		RemoveMedia:   binding("remove media", "ctrl+x"),
+		PrevPost:      binding("previous post", "ctrl+up", "ctrl+b"),
+		NextPost:      binding("next post", "ctrl+down", "ctrl+f"),
+
+		OutlineAdd:       binding("add post", "n"),
+		OutlineAddBefore: binding("add post before", "N"),
+		OutlineDuplicate: binding("duplicate post", "d"),
+		OutlineMerge:     binding("merge with next", "m"),
+		OutlineDelete:    binding("delete post", "x", "delete"),
+		OutlineMoveUp:    binding("move post up", "K"),
+		OutlineMoveDown:  binding("move post down", "J"),
+
+		FilterUp:    binding("up", "up", "ctrl+p"),
+		FilterDown:  binding("down", "down", "ctrl+n"),
+		ToggleFile:  binding("select file", "tab"),
+		ParentDir:   binding("parent folder", "backspace"),
+		RecentDir:   binding("recent folder", "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"),
+		ToggleQuote: binding("reply/quote", "tab", "shift+tab"),
+
+		NextField:   binding("next field", "tab", "down"),
+		PrevField:   binding("previous field", "shift+tab", "up"),
+		ShorterPoll: binding("shorter poll", "left"),
+		LongerPoll:  binding("longer poll", "right"),
+		RemovePoll:  binding("remove poll", "ctrl+x"),
+
+		SortStats:    binding("sort", "s"),
+		RefreshStats: binding("refresh", "r"),
+		ExportStats:  binding("export CSV", "e"),
+		Undo:         binding("undo (delete)", "u"),
+		NewPost:      binding("new post", "n"),
+	}
+	// The nth key opens the nth recent folder; list them as a range
+	k.RecentDir.SetHelp("alt+1…9", "recent folder")
+	return k
+}
+
+// sections lists every action by screen, for keys.json and the full help
+func (k *keyMap) sections() []keySection {
+	return []keySection{
+		{sectionGeneral, []keyAction{
+			{"help", &k.Help},
+			{"back", &k.Back},
+			{"close", &k.Close},
+			{"quit", &k.Quit},
+			{"confirm", &k.Confirm},
+			{"decline", &k.Decline},
+		}},
+		{sectionLists, []keyAction{
+			{"up", &k.Up},
+			{"down", &k.Down},
+			{"select", &k.Select},
+			{"page_up", &k.PageUp},
+			{"page_down", &k.PageDown},
+		}},
+		{sectionCommits, []keyAction{
+			{"toggle_commit", &k.ToggleCommit},
+			{"select_all", &k.SelectAll},
+			{"search", &k.Search},
+			{"focus_prompt", &k.FocusPrompt},
+			{"toggle_thread", &k.ToggleThread},
+			{"generate", &k.Generate},
+			{"details", &k.Details},
+			{"scroll_up", &k.ScrollUp},
+			{"scroll_down", &k.ScrollDown},
+		}},
+		{sectionCompose, []keyAction{
+			{"send", &k.Send},
+			{"regenerate", &k.Regenerate},
+			{"attach", &k.AttachFile},
+			{"paste_image", &k.PasteImage},
+			{"code_image", &k.CodeImage},
+			{"poll", &k.Poll},
+			{"editor", &k.Editor},
+			{"split", &k.Split},
+			{"split_numbered", &k.SplitNumbered},
+			{"insert_after", &k.InsertAfter},
+			{"insert_before", &k.InsertBefore},
+			{"delete_post", &k.DeletePost},
+			{"duplicate", &k.Duplicate},
+			{"merge", &k.Merge},
+			{"move_up", &k.MoveUp},
+			{"move_down", &k.MoveDown},
+			{"outline", &k.Outline},
+			{"remove_media", &k.RemoveMedia},
+			{"prev_post", &k.PrevPost},
+			{"next_post", &k.NextPost},
+		}},
+		{sectionOutline, []keyAction{
+			{"outline_add", &k.OutlineAdd},
+			{"outline_add_before", &k.OutlineAddBefore},
+			{"outline_duplicate", &k.OutlineDuplicate},
+			{"outline_merge", &k.OutlineMerge},
+			{"outline_delete", &k.OutlineDelete},
+			{"outline_move_up", &k.OutlineMoveUp},
+			{"outline_move_down", &k.OutlineMoveDown},
+		}},
+		{sectionPickers, []keyAction{
+			{"filter_up", &k.FilterUp},
+			{"filter_down", &k.FilterDown},
+			{"toggle_file", &k.ToggleFile},
+			{"parent_dir", &k.ParentDir},
+			{"recent_dir", &k.RecentDir},
+			{"toggle_quote", &k.ToggleQuote},
+		}},
+		{sectionPoll, []keyAction{
+			{"next_field", &k.NextField},
+			{"prev_field", &k.PrevField},
+			{"shorter_poll", &k.ShorterPoll},
+			{"longer_poll", &k.LongerPoll},
+			{"remove_poll", &k.RemovePoll},
+		}},
+		{sectionPosts, []keyAction{
+			{"sort_stats", &k.SortStats},
+			{"refresh_stats", &k.RefreshStats},
+			{"export_stats", &k.ExportStats},
+			{"undo", &k.Undo},
+			{"new_post", &k.NewPost},
+		}},
+	}
+}
+
+// loadKeyMap returns the default keymap with the user's overrides applied
+func loadKeyMap() (keyMap, error) {
+	k := defaultKeyMap()
+	overrides, err := config.LoadKeys()
+	if err != nil {
+		return k, err
+	}
+
+	actions := make(map[string]*key.Binding)
+	for _, section := range k.sections() {
+		for _, a := range section.actions {
+			actions[a.name] = a.binding
+		}
+	}
+
+	var unknown []string
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		desc := b.Help().Desc
+		b.SetKeys(keys...)
+		b.SetHelp(keyLabel(keys), desc)
+		b.SetEnabled(len(keys) > 0)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		path, _ := config.KeysPath()
+		return k, fmt.Errorf("unknown action(s) in %s: %s", path, strings.Join(unknown, ", "))
+	}
+	return k, nil
+}
+
+// keyLabel formats keys for help, e.g. "↑/k"
+func keyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = keyName(k)
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyNames are the symbols shown for arrow keys and space
+var keyNames = map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→", " ": "space"}
+
+// keyName shortens a key for display, e.g. "alt+up" becomes "alt+↑"
+func keyName(k string) string {
+	i := strings.LastIndex(k, "+") + 1
+	if name, ok := keyNames[k[i:]]; ok {
+		return k[:i] + name
+	}
+	return k
+}
+
+// helpFor builds a help bar item from the first key of each binding, e.g.
+// "↑/↓ navigate". Disabled bindings are left out; if all are, the item is
+// empty and renderHelpBar skips it.
+func helpFor(desc string, bindings ...key.Binding) helpItem {
+	var keys []string
+	for _, b := range bindings {
+		if b.Enabled() && len(b.Keys()) > 0 {
+			keys = append(keys, keyName(b.Keys()[0]))
+		}
+	}
+	if len(keys) == 0 {
+		return helpItem{}
+	}
+	return helpItem{strings.Join(keys, "/"), desc}
+}
+
+// typingHelp is the help bar item for the help key on screens where printable
+// keys like "?" type text, so only the others open the full help
+func typingHelp(help key.Binding) helpItem {
+	for _, k := range help.Keys() {
+		if len([]rune(k)) > 1 && help.Enabled() {
+			return helpItem{keyName(k), "all keys"}
+		}
+	}
+	return helpItem{}
+}
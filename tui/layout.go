@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Terminal size thresholds. Below the minimum nothing useful fits; below the
+// compact size optional text is dropped; from the wide size up the commit
+// browser shows a preview pane next to the list.
+const (
+	minTerminalWidth  = 40
+	minTerminalHeight = 12
+	compactWidth      = 80
+	compactHeight     = 24
+	wideWidth         = 140
+	wideHeight        = 30
+)
+
+// Size assumed before the first WindowSizeMsg arrives
+const (
+	defaultWidth  = 100
+	defaultHeight = 30
+)
+
+// size returns the terminal size, or a default before it is known
+func (m Model) size() (width, height int) {
+	width, height = m.width, m.height
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if height <= 0 {
+		height = defaultHeight
+	}
+	return width, height
+}
+
+// compact reports whether the terminal is too small for the full layout
+func (m Model) compact() bool {
+	width, height := m.size()
+	return width < compactWidth || height < compactHeight
+}
+
+// wide reports whether the commit browser has room for a preview pane
+func (m Model) wide() bool {
+	width, height := m.size()
+	return width >= wideWidth && height >= wideHeight
+}
+
+// contentWidth is the width available for text, leaving a small margin
+func (m Model) contentWidth() int {
+	width, _ := m.size()
+	return width - 2
+}
+
+// boxWidth is the width of text inside a bordered box, capped at limit
+func (m Model) boxWidth(limit int) int {
+	return min(limit, max(20, m.contentWidth()-4))
+}
+
+// panes splits the width between the commit list and the preview pane
+func (m Model) panes() (left, right int) {
+	width := m.contentWidth()
+	left = min(80, width*9/20)
+	return left, width - left - 2
+}
+
+// resize fits the text inputs to the terminal
+func (m *Model) resize() {
+	compact := m.compact()
+
+	m.textarea.SetWidth(m.boxWidth(60))
+	m.askInput.SetWidth(m.boxWidth(55))
+	if m.wide() {
+		left, _ := m.panes()
+		m.commitPromptInput.SetWidth(min(55, left-4))
+	} else {
+		m.commitPromptInput.SetWidth(m.boxWidth(55))
+	}
+
+	m.commitSearch.Width = max(10, min(60, m.contentWidth()-16))
+
+	if compact {
+		m.textarea.SetHeight(3)
+		m.askInput.SetHeight(2)
+	} else {
+		m.textarea.SetHeight(5)
+		m.askInput.SetHeight(3)
+	}
+
+	m.scrollCommits()
+}
+
+// commitBodyHeight is the height between the commit browser's search bar
+// and its help bar, shared by the list, prompt and thread toggle
+func (m Model) commitBodyHeight() int {
+	_, height := m.size()
+	return height - textHeight(m.renderHeader()) - textHeight(m.renderCommitBrowserTop()) -
+		lipgloss.Height(m.renderHelpBar(m.commitBrowserHelpItems()))
+}
+
+// visibleCommits is how many commits fit in the browser once the prompt,
+// thread toggle and scroll indicators are drawn
+func (m Model) visibleCommits() int {
+	return max(3, m.commitBodyHeight()-textHeight(m.renderCommitBrowserBottom())-2)
+}
+
+// scrollCommits keeps the commit cursor inside the visible window
+func (m *Model) scrollCommits() {
+	visible := m.visibleCommits()
+	if m.commitCursor < m.commitScrollOffset {
+		m.commitScrollOffset = m.commitCursor
+	}
+	if m.commitCursor >= m.commitScrollOffset+visible {
+		m.commitScrollOffset = m.commitCursor - visible + 1
+	}
+	// Use the space freed when the terminal grows
+	if extra := m.commitScrollOffset + visible - len(m.filteredCommits); extra > 0 {
+		m.commitScrollOffset = max(0, m.commitScrollOffset-extra)
+	}
+}
+
+// wrapHelp lays help bar items out on as many lines as the width needs
+func wrapHelp(parts []string, width int) string {
+	const gap = "   "
+	var lines []string
+	var line string
+	for _, part := range parts {
+		switch {
+		case line == "":
+			line = part
+		case lipgloss.Width(line+gap+part) > width:
+			lines = append(lines, line)
+			line = part
+		default:
+			line += gap + part
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textHeight counts the lines of s, which ends with a newline
+func textHeight(s string) int {
+	return strings.Count(s, "\n")
+}
+
+// tail keeps the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// head keeps the first n lines of s
+func head(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[:max(0, n)]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tomswokowski/shippost/x"
+)
+
+// pollDurations are the poll lengths offered in the editor, in minutes
+var pollDurations = []int{60, 6 * 60, 24 * 60, 3 * 24 * 60, 7 * 24 * 60}
+
+// pollEditor edits the choices and duration of a post's poll
+type pollEditor struct {
+	inputs   []textinput.Model // one per choice
+	focus    int               // focused choice, or len(inputs) for the duration
+	duration int               // index into pollDurations
+	err      error
+}
+
+func newPollEditor() pollEditor {
+	inputs := make([]textinput.Model, x.MaxPollOptions)
+	for i := range inputs {
+		in := textinput.New()
+		in.Placeholder = fmt.Sprintf("Choice %d", i+1)
+		if i >= x.MinPollOptions {
+			in.Placeholder += " (optional)"
+		}
+		in.CharLimit = x.MaxPollOptionLength
+		in.Width = x.MaxPollOptionLength + 2
+		inputs[i] = in
+	}
+	return pollEditor{inputs: inputs}
+}
+
+// open loads poll into the editor, or resets it for a new poll
+func (e *pollEditor) open(poll *x.Poll) tea.Cmd {
+	e.err = nil
+	e.duration = indexOf(pollDurations, x.DefaultPollMinutes)
+	for i := range e.inputs {
+		e.inputs[i].SetValue("")
+	}
+	if poll != nil {
+		for i, option := range poll.Options {
+			if i < len(e.inputs) {
+				e.inputs[i].SetValue(option)
+			}
+		}
+		if d := indexOf(pollDurations, poll.DurationMinutes); d >= 0 {
+			e.duration = d
+		}
+	}
+	return e.setFocus(0)
+}
+
+// setFocus moves focus to a choice, or to the duration selector
+func (e *pollEditor) setFocus(i int) tea.Cmd {
+	e.focus = (i + len(e.inputs) + 1) % (len(e.inputs) + 1)
+	var cmd tea.Cmd
+	for j := range e.inputs {
+		if j == e.focus {
+			cmd = e.inputs[j].Focus()
+		} else {
+			e.inputs[j].Blur()
+		}
+	}
+	return cmd
+}
+
+// poll builds a poll from the filled-in choices and validates it
+func (e *pollEditor) poll() (*x.Poll, error) {
+	poll := &x.Poll{DurationMinutes: pollDurations[e.duration]}
+	for _, in := range e.inputs {
+		if option := strings.TrimSpace(in.Value()); option != "" {
+			poll.Options = append(poll.Options, option)
+		}
+	}
+	if err := poll.Validate(); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+func (m Model) handlePollEditorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := &m.pollEditor
+	onDuration := e.focus == len(e.inputs)
+
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back):
+		return m.closePollEditor()
+	case key.Matches(msg, k.NextField):
+		return m, e.setFocus(e.focus + 1)
+	case key.Matches(msg, k.PrevField):
+		return m, e.setFocus(e.focus - 1)
+	case key.Matches(msg, k.ShorterPoll, k.LongerPoll) && onDuration:
+		delta := 1
+		if key.Matches(msg, k.ShorterPoll) {
+			delta = -1
+		}
+		e.duration = (e.duration + delta + len(pollDurations)) % len(pollDurations)
+		return m, nil
+	case key.Matches(msg, k.RemovePoll):
+		m.thread[m.currentPost].poll = nil
+		return m.closePollEditor()
+	case key.Matches(msg, k.Select):
+		poll, err := e.poll()
+		if err != nil {
+			e.err = err
+			return m, nil
+		}
+		m.thread[m.currentPost].poll = poll
+		return m.closePollEditor()
+	}
+
+	if onDuration {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	e.inputs[e.focus], cmd = e.inputs[e.focus].Update(msg)
+	e.err = nil
+	return m, cmd
+}
+
+// closePollEditor returns to compose
+func (m Model) closePollEditor() (tea.Model, tea.Cmd) {
+	for i := range m.pollEditor.inputs {
+		m.pollEditor.inputs[i].Blur()
+	}
+	m.state = m.composeState()
+	m.textarea.Focus()
+	return m, textarea.Blink
+}
+
+func indexOf(values []int, v int) int {
+	for i, value := range values {
+		if value == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// clonePoll copies a poll so duplicated posts can be edited independently
+func clonePoll(poll *x.Poll) *x.Poll {
+	if poll == nil {
+		return nil
+	}
+	return &x.Poll{Options: append([]string(nil), poll.Options...), DurationMinutes: poll.DurationMinutes}
+}
@@ -0,0 +1,519 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/color/palette"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Thumbnail size in terminal cells
+const (
+	thumbCols = 24
+	thumbRows = 8
+
+	// Approximate pixel size of a terminal cell, used by pixel-based protocols
+	cellPixelWidth  = 10
+	cellPixelHeight = 20
+)
+
+// imageProtocol is how images are drawn in the terminal
+type imageProtocol int
+
+const (
+	protocolNone imageProtocol = iota
+	protocolHalfBlocks
+	protocolKitty
+	protocolITerm
+	protocolSixel
+)
+
+// termImageProtocol is detected once at startup
+var termImageProtocol = detectImageProtocol()
+
+// detectImageProtocol picks the best image protocol the terminal supports.
+// SHIPPOST_IMAGE_PROTOCOL (kitty, iterm, sixel, blocks or none) overrides it.
+func detectImageProtocol() imageProtocol {
+	switch strings.ToLower(os.Getenv("SHIPPOST_IMAGE_PROTOCOL")) {
+	case "kitty":
+		return protocolKitty
+	case "iterm":
+		return protocolITerm
+	case "sixel":
+		return protocolSixel
+	case "blocks":
+		return protocolHalfBlocks
+	case "none":
+		return protocolNone
+	}
+
+	// Graphics escapes don't pass through tmux/screen without extra setup
+	term := os.Getenv("TERM")
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen") {
+		return protocolHalfBlocks
+	}
+
+	termProgram := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || termProgram == "ghostty":
+		return protocolKitty
+	case termProgram == "iTerm.app" || termProgram == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return protocolITerm
+	case strings.HasPrefix(term, "foot") || strings.Contains(term, "sixel") || termProgram == "mlterm":
+		return protocolSixel
+	}
+	return protocolHalfBlocks
+}
+
+// kittyUploadDelay is how long an image upload stays in the view, so a frame
+// carrying it has been written before the image is only placed by id
+const kittyUploadDelay = 250 * time.Millisecond
+
+// kittyImages tracks the images sent to a Kitty terminal, so each is uploaded
+// once and then placed by id. It is shared by copies of the Model.
+type kittyImages struct {
+	uploaded map[uint32]bool // false while the upload waits to reach the screen
+	stale    map[uint32]bool // placed earlier, no longer on screen
+}
+
+func newKittyImages() *kittyImages {
+	return &kittyImages{uploaded: make(map[uint32]bool), stale: make(map[uint32]bool)}
+}
+
+type kittyUploadedMsg struct {
+	id uint32
+}
+
+// mediaPreview is a rendered thumbnail with details about the image
+type mediaPreview struct {
+	thumb   string // rendered thumbnail, thumbRows lines tall
+	width   int
+	height  int
+	format  string
+	size    int64
+	modTime time.Time // of the file the preview was rendered from
+	loading bool      // still being rendered in the background
+	err     error
+
+	// Kitty thumbnails are uploaded once and thumb only places them
+	kittyID uint32
+	upload  string
+}
+
+type previewRenderedMsg struct {
+	path    string
+	preview *mediaPreview
+}
+
+// info describes the image, e.g. "1920×1080 · 420 KB · PNG"
+func (p *mediaPreview) info() string {
+	if p.loading {
+		return "loading preview..."
+	}
+	if p.err != nil {
+		return p.err.Error()
+	}
+	return fmt.Sprintf("%d×%d · %s · %s", p.width, p.height, formatBytes(p.size), strings.ToUpper(p.format))
+}
+
+// previewFor returns the cached preview of the image at path. Until
+// loadPreviews has rendered the current version of the file it returns a
+// placeholder, so drawing the view never decodes an image.
+func (m Model) previewFor(path string) *mediaPreview {
+	if p, ok := m.previews[path]; ok && !p.loading && p.modTime.Equal(fileModTime(path)) {
+		return p
+	}
+	return &mediaPreview{loading: true}
+}
+
+// fileModTime returns when the file at path was last modified, or the zero
+// time if it can't be read
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// previewPaths returns the images whose previews the current screen shows
+func (m Model) previewPaths() []string {
+	switch m.state {
+	case stateCompose, stateSmartCompose, statePosting:
+		if m.currentPost < len(m.thread) {
+			return m.thread[m.currentPost].media
+		}
+	case stateMediaInput:
+		if entry, ok := m.picker.current(); ok && !entry.isDir && !isTypedPath(m.picker.input.Value()) {
+			return []string{filepath.Join(m.picker.dir, entry.name)}
+		}
+	}
+	return nil
+}
+
+// thumbnailPaths returns the images drawn as thumbnails on screen right now
+func (m Model) thumbnailPaths() []string {
+	if termImageProtocol == protocolNone || m.showHelp {
+		return nil
+	}
+	if m.compact() && m.state != stateMediaInput {
+		return nil
+	}
+	return m.previewPaths()
+}
+
+// loadPreviews renders, in the background, the previews the current screen
+// needs that are missing or older than their file
+func (m Model) loadPreviews() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, path := range m.previewPaths() {
+		modTime := fileModTime(path)
+		if p, ok := m.previews[path]; ok && p.modTime.Equal(modTime) {
+			continue // rendered or on its way
+		}
+		m.previews[path] = &mediaPreview{loading: true, modTime: modTime}
+		cmds = append(cmds, func() tea.Msg {
+			p := renderPreview(path, termImageProtocol, kittyImageID(path, modTime))
+			p.modTime = modTime
+			return previewRenderedMsg{path: path, preview: p}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// kittyIDs returns the Kitty images on screen right now
+func (m Model) kittyIDs() []uint32 {
+	var ids []uint32
+	for _, path := range m.thumbnailPaths() {
+		if id := m.previewFor(path).kittyID; id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// trackThumbnails notes the Kitty images a screen change hid, so the next
+// view deletes their placements, and starts the upload of new ones. Other
+// protocols draw into the cells and are overwritten.
+func trackThumbnails(before, after Model) tea.Cmd {
+	if termImageProtocol != protocolKitty || after.kitty == nil {
+		return nil
+	}
+	k := after.kitty
+	shown := after.kittyIDs()
+	for _, id := range before.kittyIDs() {
+		if !slices.Contains(shown, id) {
+			k.stale[id] = true
+		}
+	}
+
+	var cmds []tea.Cmd
+	for _, id := range shown {
+		delete(k.stale, id)
+		if _, ok := k.uploaded[id]; !ok {
+			k.uploaded[id] = false
+			cmds = append(cmds, tea.Tick(kittyUploadDelay, func(time.Time) tea.Msg {
+				return kittyUploadedMsg{id: id}
+			}))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// kittyUploaded records that an upload reached the screen. An image that
+// was hidden meanwhile may never have been drawn, so it's uploaded again
+// the next time it's shown.
+func (m Model) kittyUploaded(id uint32) {
+	if slices.Contains(m.kittyIDs(), id) {
+		m.kitty.uploaded[id] = true
+	} else {
+		delete(m.kitty.uploaded, id)
+	}
+}
+
+// kittyDeletes removes the placements of hidden Kitty images, keeping their
+// data for when they're shown again. The view starts with it, so it reaches
+// the terminal in order with the frame rather than from another goroutine.
+func (m Model) kittyDeletes() string {
+	if m.kitty == nil || len(m.kitty.stale) == 0 {
+		return ""
+	}
+	ids := slices.Sorted(maps.Keys(m.kitty.stale))
+	var b strings.Builder
+	for _, id := range ids {
+		b.WriteString(fmt.Sprintf("\x1b_Ga=d,d=i,i=%d,q=2\x1b\\", id))
+	}
+	return b.String()
+}
+
+// kittyImageID names the image at path for the Kitty protocol. A changed
+// file gets a new id.
+func kittyImageID(path string, modTime time.Time) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	h.Write([]byte(modTime.String()))
+	return max(h.Sum32(), 1)
+}
+
+// renderPreview decodes an image and renders a thumbnail with the given
+// protocol. kittyID names the image for the Kitty protocol.
+func renderPreview(path string, protocol imageProtocol, kittyID uint32) *mediaPreview {
+	p := &mediaPreview{}
+
+	f, err := os.Open(path)
+	if err != nil {
+		p.err = fmt.Errorf("cannot open %s", filepath.Base(path))
+		return p
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil {
+		p.size = info.Size()
+	}
+
+	img, format, err := image.Decode(f)
+	if err != nil {
+		p.err = fmt.Errorf("cannot decode %s", filepath.Base(path))
+		return p
+	}
+	p.format = format
+	p.width = img.Bounds().Dx()
+	p.height = img.Bounds().Dy()
+
+	switch protocol {
+	case protocolKitty:
+		p.kittyID = kittyID
+		p.upload, p.thumb = kittyImage(img, kittyID)
+	case protocolITerm:
+		p.thumb = itermImage(img)
+	case protocolSixel:
+		p.thumb = sixelImage(img)
+	case protocolHalfBlocks:
+		p.thumb = halfBlockImage(img)
+	}
+	return p
+}
+
+// renderThumbnails draws previews side by side, each captioned with its details
+func (m Model) renderThumbnails(previews []*mediaPreview, captions []string) string {
+	if termImageProtocol == protocolNone || len(previews) == 0 {
+		return ""
+	}
+
+	const gap = 2
+	var row string
+	if termImageProtocol == protocolHalfBlocks {
+		var cols []string
+		for i, p := range previews {
+			if i > 0 {
+				cols = append(cols, strings.Repeat(" ", gap))
+			}
+			cols = append(cols, lipgloss.NewStyle().Width(thumbCols).Height(thumbRows).Render(p.thumb))
+		}
+		row = lipgloss.JoinHorizontal(lipgloss.Top, cols...)
+	} else {
+		// Graphics are drawn at saved cursor positions, then blank lines
+		// reserve the space so the rest of the view flows below them
+		var b strings.Builder
+		for i, p := range previews {
+			b.WriteString("\x1b7")
+			if offset := i * (thumbCols + gap); offset > 0 {
+				b.WriteString(fmt.Sprintf("\x1b[%dC", offset))
+			}
+			if p.kittyID != 0 && m.kitty != nil && !m.kitty.uploaded[p.kittyID] {
+				b.WriteString(p.upload)
+			}
+			b.WriteString(p.thumb)
+			b.WriteString("\x1b8")
+		}
+		b.WriteString(strings.Repeat("\n", thumbRows-1))
+		row = b.String()
+	}
+
+	var caps []string
+	for i, caption := range captions {
+		if i > 0 {
+			caps = append(caps, strings.Repeat(" ", gap))
+		}
+		caps = append(caps, dimStyle.Width(thumbCols).MaxWidth(thumbCols).Render(caption))
+	}
+	return row + "\n" + lipgloss.JoinHorizontal(lipgloss.Top, caps...)
+}
+
+// fitSize scales w×h to fit within maxW×maxH, keeping the aspect ratio
+func fitSize(w, h, maxW, maxH int) (int, int) {
+	if w <= 0 || h <= 0 {
+		return 1, 1
+	}
+	scale := min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+	return max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+}
+
+// scaleImage resizes img to fit within maxW×maxH pixels over a dark background
+func scaleImage(img image.Image, maxW, maxH int) *image.RGBA {
+	w, h := fitSize(img.Bounds().Dx(), img.Bounds().Dy(), maxW, maxH)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.RGBA{0x1E, 0x29, 0x3B, 0xFF}), image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// halfBlockImage renders img with "▀" characters: the foreground colours the
+// top pixel of each cell and the background the bottom pixel
+func halfBlockImage(img image.Image) string {
+	thumb := scaleImage(img, thumbCols, thumbRows*2)
+	bounds := thumb.Bounds()
+
+	var lines []string
+	for y := 0; y < bounds.Dy(); y += 2 {
+		var line strings.Builder
+		for x := 0; x < bounds.Dx(); x++ {
+			style := lipgloss.NewStyle().Foreground(hexColor(thumb.At(x, y)))
+			if y+1 < bounds.Dy() {
+				style = style.Background(hexColor(thumb.At(x, y+1)))
+			}
+			line.WriteString(style.Render("▀"))
+		}
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func hexColor(c color.Color) lipgloss.Color {
+	r, g, b, _ := c.RGBA()
+	return lipgloss.Color(fmt.Sprintf("#%02X%02X%02X", r>>8, g>>8, b>>8))
+}
+
+// kittyImage encodes img with the Kitty graphics protocol: upload transmits
+// it under id, and place draws it without moving the cursor
+func kittyImage(img image.Image, id uint32) (upload, place string) {
+	thumb := scaleImage(img, thumbCols*cellPixelWidth, thumbRows*cellPixelHeight)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, thumb); err != nil {
+		return "", ""
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// Payloads are sent in chunks of at most 4096 bytes
+	const chunkSize = 4096
+	var b strings.Builder
+	for i := 0; i < len(data); i += chunkSize {
+		end := min(i+chunkSize, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			b.WriteString(fmt.Sprintf("\x1b_Ga=t,f=100,i=%d,q=2,m=%d;", id, more))
+		} else {
+			b.WriteString(fmt.Sprintf("\x1b_Gm=%d;", more))
+		}
+		b.WriteString(data[i:end])
+		b.WriteString("\x1b\\")
+	}
+	// A fixed placement id makes drawing it again replace the old placement
+	place = fmt.Sprintf("\x1b_Ga=p,i=%d,p=1,q=2,C=1,c=%d,r=%d\x1b\\", id, thumbCols, thumbRows)
+	return b.String(), place
+}
+
+// itermImage encodes img with the iTerm2 inline images protocol. It has no
+// image ids, so the image is sent again whenever its line is redrawn.
+func itermImage(img image.Image) string {
+	thumb := scaleImage(img, thumbCols*cellPixelWidth, thumbRows*cellPixelHeight)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, thumb); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		buf.Len(), thumbCols, thumbRows, base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// sixelImage encodes img as DEC sixel graphics using a 256-colour palette
+func sixelImage(img image.Image) string {
+	thumb := scaleImage(img, thumbCols*cellPixelWidth, thumbRows*cellPixelHeight)
+	bounds := thumb.Bounds()
+	pal := image.NewPaletted(bounds, palette.Plan9)
+	draw.FloydSteinberg.Draw(pal, bounds, thumb, image.Point{})
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\x1bPq\"1;1;%d;%d", bounds.Dx(), bounds.Dy()))
+	for i, c := range pal.Palette {
+		r, g, bl, _ := c.RGBA()
+		b.WriteString(fmt.Sprintf("#%d;2;%d;%d;%d", i, r*100/0xFFFF, g*100/0xFFFF, bl*100/0xFFFF))
+	}
+
+	// Each band is six pixel rows; every colour used in the band is drawn in
+	// its own pass, returning to the start of the band with "$"
+	for y := 0; y < bounds.Dy(); y += 6 {
+		used := make(map[uint8]bool)
+		for yy := y; yy < min(y+6, bounds.Dy()); yy++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				used[pal.ColorIndexAt(x, yy)] = true
+			}
+		}
+		for idx := range used {
+			b.WriteString(fmt.Sprintf("#%d", idx))
+			var run byte
+			count := 0
+			flush := func() {
+				switch {
+				case count > 3:
+					b.WriteString(fmt.Sprintf("!%d%c", count, run))
+				case count > 0:
+					b.WriteString(strings.Repeat(string(run), count))
+				}
+			}
+			for x := 0; x < bounds.Dx(); x++ {
+				var bits byte
+				for bit := 0; bit < 6 && y+bit < bounds.Dy(); bit++ {
+					if pal.ColorIndexAt(x, y+bit) == idx {
+						bits |= 1 << bit
+					}
+				}
+				ch := '?' + bits
+				if ch == run {
+					count++
+					continue
+				}
+				flush()
+				run, count = ch, 1
+			}
+			flush()
+			b.WriteString("$")
+		}
+		b.WriteString("-")
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// formatBytes renders a file size like "1.2 MB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tomswokowski/shippost/x"
+)
+
+// reference is an existing post being replied to or quoted
+type reference struct {
+	quote      bool
+	continuing bool // post is the last of our own thread being continued
+	post       *x.Post
+}
+
+// verb describes the reference for labels, e.g. "Replying to"
+func (r *reference) verb() string {
+	switch {
+	case r.quote:
+		return "Quoting"
+	case r.continuing:
+		return "Continuing thread after"
+	}
+	return "Replying to"
+}
+
+type postFetchedMsg struct {
+	post *x.Post
+	err  error
+}
+
+func newReferenceInput() textinput.Model {
+	in := textinput.New()
+	in.Placeholder = "https://x.com/user/status/123… or a post ID"
+	in.Width = 60
+	in.CharLimit = 512
+	return in
+}
+
+func (m Model) fetchPost(id string) tea.Cmd {
+	client := m.xClient
+	return func() tea.Msg {
+		post, err := client.GetPost(id)
+		if err != nil {
+			return postFetchedMsg{err: fmt.Errorf("failed to fetch post: %w", err)}
+		}
+		return postFetchedMsg{post: post}
+	}
+}
+
+func (m Model) handleReferenceInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back):
+		m.referenceInput.Blur()
+		m.state = stateHome
+		m.status = ""
+		m.err = nil
+		return m, nil
+	case key.Matches(msg, k.ToggleQuote):
+		m.referenceQuote = !m.referenceQuote
+		return m, nil
+	case key.Matches(msg, k.Select):
+		if m.status != "" {
+			return m, nil // already fetching
+		}
+		id, err := x.ParsePostID(m.referenceInput.Value())
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		m.status = "Fetching post..."
+		return m, m.fetchPost(id)
+	}
+
+	var cmd tea.Cmd
+	m.referenceInput, cmd = m.referenceInput.Update(msg)
+	m.err = nil
+	return m, cmd
+}
+
+// handlePostFetched opens compose with the fetched post as context
+func (m Model) handlePostFetched(msg postFetchedMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	if m.state != stateReferenceInput && m.state != stateContinueThread {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+
+	// A post from the continue screen wasn't in the history, so reply to it
+	quote := m.state == stateReferenceInput && m.referenceQuote
+	return m.composeReference(&reference{quote: quote, post: msg.post})
+}
+
+func (m Model) viewReferenceInput(b *strings.Builder) {
+	b.WriteString(subtitleStyle.Render("Reply or Quote"))
+	b.WriteString("\n\n")
+
+	for _, mode := range []struct {
+		label string
+		quote bool
+	}{{"Reply", false}, {"Quote", true}} {
+		if mode.quote == m.referenceQuote {
+			b.WriteString(selectedStyle.Render("● " + mode.label))
+		} else {
+			b.WriteString(dimStyle.Render("○ " + mode.label))
+		}
+		b.WriteString("   ")
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Post URL or ID"))
+	b.WriteString("\n")
+	b.WriteString(activeBoxStyle.Render(m.referenceInput.View()))
+	b.WriteString("\n")
+
+	if m.status != "" {
+		b.WriteString("\n")
+		b.WriteString(statusStyle.Render("● " + m.status))
+		b.WriteString("\n")
+	}
+	if m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("✗ " + m.err.Error()))
+		b.WriteString("\n")
+	}
+
+	k := m.keys
+	b.WriteString(m.renderHelpBar([]helpItem{
+		helpFor("reply/quote", k.ToggleQuote),
+		helpFor("fetch post", k.Select),
+		helpFor("back", k.Back),
+	}))
+}
+
+// renderReference shows the post being replied to or quoted above the compose box
+func (m Model) renderReference(b *strings.Builder) {
+	r := m.reference
+	author := "@" + r.post.AuthorUsername
+	switch {
+	case r.post.AuthorUsername != "":
+	case r.continuing:
+		author = "your post"
+	default:
+		author = "post " + r.post.ID
+	}
+	b.WriteString(dimStyle.Render(r.verb() + " "))
+	b.WriteString(inputLabelStyle.Render(author))
+	if r.post.AuthorName != "" {
+		b.WriteString(dimStyle.Render(" (" + r.post.AuthorName + ")"))
+	}
+	b.WriteString("\n")
+	b.WriteString(boxStyle.Width(m.boxWidth(60)).Render(dimStyle.Render(r.post.Text)))
+	b.WriteString("\n")
+}
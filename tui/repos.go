@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tomswokowski/shippost/git"
+)
+
+// maxRepoLabelWidth caps the repo column of the commit browser
+const maxRepoLabelWidth = 14
+
+// loadRepos opens the repositories Smart Post reads from: the paths given
+// with --repo, else the repos in the config, else the repository of the
+// current directory if there is one. A --repo path that isn't a repository is
+// an error; a configured one is skipped and returned in skipped, so one moved
+// or deleted repo doesn't stop shippost from starting. If none of them open,
+// the current directory is used. backend chooses how the repositories are read.
+func loadRepos(flagPaths, configPaths []string, backend git.Backend) (repos []git.Repo, skipped []error, err error) {
+	paths := flagPaths
+	if len(paths) == 0 {
+		paths = configPaths
+	}
+
+	for _, path := range paths {
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			home, _ := os.UserHomeDir()
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+		repo, err := git.Open(path, backend)
+		if err != nil && len(flagPaths) == 0 {
+			skipped = append(skipped, fmt.Errorf("skipped repo from config: %w", err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if slices.ContainsFunc(repos, func(r git.Repo) bool { return r.Dir == repo.Dir }) {
+			continue
+		}
+		// Tell apart repos with the same directory name by their parent
+		if slices.ContainsFunc(repos, func(r git.Repo) bool { return r.Name == repo.Name }) {
+			repo.Name = filepath.Join(filepath.Base(filepath.Dir(repo.Dir)), repo.Name)
+		}
+		repos = append(repos, repo)
+	}
+	if len(repos) > 0 {
+		return repos, skipped, nil
+	}
+
+	repo, err := git.Open(".", backend)
+	if err != nil {
+		return nil, skipped, nil
+	}
+	return []git.Repo{repo}, skipped, nil
+}
+
+// repoLabelWidth is the width of the commit browser's repo column, or 0
+// when commits come from a single repository
+func (m Model) repoLabelWidth() int {
+	if len(m.repos) < 2 {
+		return 0
+	}
+	width := 0
+	for _, r := range m.repos {
+		width = max(width, len(r.Name))
+	}
+	return min(width, maxRepoLabelWidth)
+}
@@ -0,0 +1,263 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tomswokowski/shippost/git"
+	"github.com/tomswokowski/shippost/snippet"
+)
+
+// maxVisibleHunks is the number of diff hunks listed at once
+const maxVisibleHunks = 8
+
+type hunksLoadedMsg struct {
+	hunks []git.Hunk
+	err   error
+}
+
+type snippetRenderedMsg struct {
+	post    int
+	version int // threadVersion when rendering started
+	path    string
+	err     error
+}
+
+// snippetPicker chooses a diff hunk or a file range to render as a code image
+type snippetPicker struct {
+	hunks   []git.Hunk
+	cursor  int
+	offset  int
+	input   textinput.Model
+	loading bool
+	err     error
+}
+
+func newSnippetPicker() snippetPicker {
+	in := textinput.New()
+	in.Placeholder = "or a file range like main.go:10-40"
+	in.Width = 50
+	in.CharLimit = 512
+	return snippetPicker{input: in}
+}
+
+// moveCursor moves the highlighted hunk, scrolling the visible window
+func (p *snippetPicker) moveCursor(delta int) {
+	p.cursor = max(0, min(len(p.hunks)-1, p.cursor+delta))
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+maxVisibleHunks {
+		p.offset = p.cursor - maxVisibleHunks + 1
+	}
+}
+
+// snippetCommits returns the commits whose hunks are offered: those the
+// draft was written from, or the latest commit after Ask
+func (m Model) snippetCommits() []git.Commit {
+	if len(m.draftCommits) > 0 {
+		return m.draftCommits
+	}
+	if len(m.commits) > 0 {
+		return m.commits[:1]
+	}
+	return nil
+}
+
+// snippetPath resolves a typed file path against the repositories of the
+// snippet commits, rather than the directory shippost was launched from. The
+// first repository containing the file wins.
+func (m Model) snippetPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	var first string
+	for _, c := range m.snippetCommits() {
+		if c.Repo.Dir == "" {
+			continue
+		}
+		full := filepath.Join(c.Repo.Dir, path)
+		if _, err := os.Stat(full); err == nil {
+			return full
+		}
+		if first == "" {
+			first = full
+		}
+	}
+	if first == "" {
+		return path
+	}
+	return first
+}
+
+// openSnippetPicker switches to the code image picker and loads the diff hunks
+func (m Model) openSnippetPicker() (tea.Model, tea.Cmd) {
+	if err := m.attachError(); err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.syncCurrentPost()
+	m.textarea.Blur()
+	m.state = stateCodeSnippet
+	m.err = nil
+
+	p := &m.snippets
+	p.hunks = nil
+	p.cursor = 0
+	p.offset = 0
+	p.err = nil
+	p.loading = true
+	p.input.SetValue("")
+	p.input.Focus()
+	return m, tea.Batch(textinput.Blink, loadHunks(m.snippetCommits()))
+}
+
+func loadHunks(commits []git.Commit) tea.Cmd {
+	return func() tea.Msg {
+		var hunks []git.Hunk
+		for _, c := range commits {
+			h, err := c.Repo.GetCommitHunks(c.Hash)
+			if err != nil {
+				return hunksLoadedMsg{err: err}
+			}
+			hunks = append(hunks, h...)
+		}
+		return hunksLoadedMsg{hunks: hunks}
+	}
+}
+
+// renderSnippet draws code to a PNG in a temp file for attaching to post
+func (m Model) renderSnippet(post, version int, code string, opts snippet.Options) tea.Cmd {
+	opts.Theme = m.cfg.SnippetTheme
+	return func() tea.Msg {
+		data, err := snippet.Render(code, opts)
+		if err != nil {
+			return snippetRenderedMsg{post: post, version: version, err: err}
+		}
+
+		f, err := os.CreateTemp("", "shippost-code-*.png")
+		if err != nil {
+			return snippetRenderedMsg{post: post, version: version, err: fmt.Errorf("failed to save code image: %w", err)}
+		}
+		defer f.Close()
+		if _, err := f.Write(data); err != nil {
+			os.Remove(f.Name())
+			return snippetRenderedMsg{post: post, version: version, err: fmt.Errorf("failed to save code image: %w", err)}
+		}
+		return snippetRenderedMsg{post: post, version: version, path: f.Name()}
+	}
+}
+
+func (m Model) handleCodeSnippetKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.snippets
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back):
+		if p.input.Value() != "" {
+			p.input.SetValue("")
+			return m, nil
+		}
+		p.input.Blur()
+		m.state = m.composeState()
+		m.textarea.Focus()
+		return m, textarea.Blink
+	case key.Matches(msg, k.FilterUp):
+		p.moveCursor(-1)
+		return m, nil
+	case key.Matches(msg, k.FilterDown):
+		p.moveCursor(1)
+		return m, nil
+	case key.Matches(msg, k.PageUp):
+		p.moveCursor(-maxVisibleHunks)
+		return m, nil
+	case key.Matches(msg, k.PageDown):
+		p.moveCursor(maxVisibleHunks)
+		return m, nil
+	case key.Matches(msg, k.Select):
+		var code string
+		var opts snippet.Options
+		if input := strings.TrimSpace(p.input.Value()); input != "" {
+			path, start, end, err := parseFileRange(input)
+			if err != nil {
+				p.err = err
+				return m, nil
+			}
+			code, start, err = readFileRange(m.snippetPath(path), start, end)
+			if err != nil {
+				p.err = err
+				return m, nil
+			}
+			opts = snippet.Options{Filename: filepath.Base(path), StartLine: start}
+		} else if p.cursor < len(p.hunks) {
+			hunk := p.hunks[p.cursor]
+			code = strings.Join(hunk.Lines, "\n")
+			opts = snippet.Options{Filename: filepath.Base(hunk.File), StartLine: hunk.NewStart, Diff: true}
+		} else {
+			return m, nil
+		}
+
+		p.input.Blur()
+		m.state = m.composeState()
+		m.status = "Rendering code image..."
+		m.attaching++
+		m.textarea.Focus()
+		return m, tea.Batch(textarea.Blink, m.renderSnippet(m.currentPost, m.threadVersion, code, opts))
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.err = nil
+	return m, cmd
+}
+
+// parseFileRange parses "path", "path:10" or "path:10-40". Missing bounds are 0.
+func parseFileRange(input string) (path string, start, end int, err error) {
+	path = input
+	i := strings.LastIndex(input, ":")
+	if i < 0 {
+		return path, 0, 0, nil
+	}
+	path, lines := input[:i], input[i+1:]
+
+	from, to, isRange := strings.Cut(lines, "-")
+	if start, err = strconv.Atoi(from); err != nil || start < 1 {
+		return "", 0, 0, fmt.Errorf("invalid line range: %s", lines)
+	}
+	end = start
+	if isRange {
+		if end, err = strconv.Atoi(to); err != nil || end < start {
+			return "", 0, 0, fmt.Errorf("invalid line range: %s", lines)
+		}
+	}
+	return path, start, end, nil
+}
+
+// readFileRange returns lines start..end of a file (1-based, inclusive) and the
+// first line number. A zero start reads from the top; a zero end reads as many
+// lines as fit in a snippet.
+func readFileRange(path string, start, end int) (string, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+
+	start = max(start, 1)
+	if start > len(lines) {
+		return "", 0, fmt.Errorf("%s has only %d lines", filepath.Base(path), len(lines))
+	}
+	if end == 0 {
+		end = start + snippet.MaxLines - 1
+	}
+	end = min(end, len(lines))
+	return strings.Join(lines[start-1:end], "\n"), start, nil
+}
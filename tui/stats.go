@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomswokowski/shippost/stats"
+	"github.com/tomswokowski/shippost/x"
+)
+
+type statsLoadedMsg struct {
+	rows []stats.Row
+	err  error
+}
+
+// statsView lists published posts with their engagement
+type statsView struct {
+	rows    []stats.Row
+	sort    int // index into stats.SortKeys
+	cursor  int
+	offset  int
+	loading bool
+	notice  string
+	err     error
+}
+
+// moveCursor moves the highlighted post, scrolling a window of visible rows
+func (v *statsView) moveCursor(delta, visible int) {
+	v.cursor = max(0, min(len(v.rows)-1, v.cursor+delta))
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+visible {
+		v.offset = v.cursor - visible + 1
+	}
+}
+
+// visibleStats is how many posts fit on the stats screen
+func (m Model) visibleStats() int {
+	_, height := m.size()
+	chrome := 12 // heading, column names, scroll indicators, details and help
+	if m.compact() {
+		chrome = 8
+	}
+	return max(3, height-lipgloss.Height(m.renderHeader())-chrome)
+}
+
+// openStats switches to the stats screen and loads metrics, from the cache
+// when they are recent enough
+func (m Model) openStats() (tea.Model, tea.Cmd) {
+	m.state = stateStats
+	m.err = nil
+	m.stats.notice = ""
+	m.stats.err = nil
+	m.stats.loading = true
+	return m, m.loadStats(stats.MaxAge)
+}
+
+func (m Model) loadStats(maxAge time.Duration) tea.Cmd {
+	client := m.xClient
+	return func() tea.Msg {
+		rows, err := stats.Load(client, maxAge)
+		return statsLoadedMsg{rows: rows, err: err}
+	}
+}
+
+func (m Model) handleStatsLoaded(msg statsLoadedMsg) (tea.Model, tea.Cmd) {
+	v := &m.stats
+	v.loading = false
+	v.err = msg.err
+	v.rows = msg.rows
+	stats.Sort(v.rows, stats.SortKeys[v.sort])
+	v.cursor = 0
+	v.offset = 0
+	return m, nil
+}
+
+func (m Model) handleStatsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.stats
+	visible := m.visibleStats()
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back, k.Close):
+		m.state = stateHome
+		return m, nil
+	case key.Matches(msg, k.Up):
+		v.moveCursor(-1, visible)
+	case key.Matches(msg, k.Down):
+		v.moveCursor(1, visible)
+	case key.Matches(msg, k.PageUp):
+		v.moveCursor(-visible, visible)
+	case key.Matches(msg, k.PageDown):
+		v.moveCursor(visible, visible)
+	case key.Matches(msg, k.SortStats):
+		v.sort = (v.sort + 1) % len(stats.SortKeys)
+		stats.Sort(v.rows, stats.SortKeys[v.sort])
+		v.cursor = 0
+		v.offset = 0
+	case key.Matches(msg, k.RefreshStats):
+		if !v.loading {
+			v.loading = true
+			v.notice = ""
+			v.err = nil
+			return m, m.loadStats(0)
+		}
+	case key.Matches(msg, k.ExportStats):
+		if len(v.rows) == 0 {
+			return m, nil
+		}
+		path, err := stats.ExportCSV(".", v.rows)
+		if err != nil {
+			v.err = err
+			return m, nil
+		}
+		v.notice = "Exported to " + path
+	}
+	return m, nil
+}
+
+func (m Model) viewStats(b *strings.Builder) {
+	v := m.stats
+
+	b.WriteString(subtitleStyle.Render("Stats"))
+	b.WriteString("  ")
+	if m.compact() {
+		b.WriteString(dimStyle.Render("by " + stats.SortKeys[v.sort]))
+	} else {
+		b.WriteString(dimStyle.Render("engagement of posts published with shippost, by " + stats.SortKeys[v.sort]))
+	}
+	b.WriteString(m.gap())
+
+	switch {
+	case v.loading:
+		b.WriteString(statusStyle.Render("● Fetching metrics..."))
+		b.WriteString("\n")
+	case len(v.rows) == 0 && v.err == nil:
+		b.WriteString(dimStyle.Render("  No posts published with shippost yet"))
+		b.WriteString("\n")
+	case len(v.rows) > 0:
+		// Compact terminals leave out the reposts and replies columns
+		compact := m.compact()
+		textWidth := max(10, m.contentWidth()-50)
+		if compact {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  %-8s %11s %7s  %s", "Date", "Impressions", "Likes", "Post")))
+			textWidth = max(10, m.contentWidth()-34)
+		} else {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  %-8s %11s %7s %7s %7s  %s", "Date", "Impressions", "Likes", "Reposts", "Replies", "Post")))
+		}
+		textWidth = min(50, textWidth)
+		b.WriteString("\n")
+
+		visible := m.visibleStats()
+		offset := max(v.offset, v.cursor-visible+1)
+		if offset > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more above", offset)))
+			b.WriteString("\n")
+		}
+		end := min(offset+visible, len(v.rows))
+		for i := offset; i < end; i++ {
+			r := v.rows[i]
+			text := truncate(strings.Join(strings.Fields(r.Text), " "), textWidth)
+			line := fmt.Sprintf("%-8s %11d %7d %7d %7d  %s",
+				r.CreatedAt.Local().Format("Jan 2"), r.Impressions, r.Likes, r.Reposts, r.Replies, text)
+			if compact {
+				line = fmt.Sprintf("%-8s %11d %7d  %s", r.CreatedAt.Local().Format("Jan 2"), r.Impressions, r.Likes, text)
+			}
+			if i == v.cursor {
+				b.WriteString(bulletStyle.Render("▸ "))
+				b.WriteString(selectedStyle.Render(line))
+			} else {
+				b.WriteString("  ")
+				b.WriteString(menuItemStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+		if remaining := len(v.rows) - end; remaining > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more below", remaining)))
+			b.WriteString("\n")
+		}
+
+		if v.cursor < len(v.rows) {
+			r := v.rows[v.cursor]
+			b.WriteString("\n")
+			b.WriteString(urlStyle.Render(x.PostURL(r.ID)))
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  %d quotes · %d bookmarks · updated %s",
+				r.Quotes, r.Bookmarks, r.FetchedAt.Local().Format("15:04"))))
+			b.WriteString("\n")
+		}
+	}
+
+	if v.notice != "" {
+		b.WriteString("\n")
+		b.WriteString(statusStyle.Render("✓ " + v.notice))
+		b.WriteString("\n")
+	}
+	if v.err != nil {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("✗ " + v.err.Error()))
+		b.WriteString("\n")
+	}
+
+	k := m.keys
+	b.WriteString(m.renderHelpBar([]helpItem{
+		helpFor("navigate", k.Up, k.Down),
+		helpFor("sort", k.SortStats),
+		helpFor("refresh", k.RefreshStats),
+		helpFor("export CSV", k.ExportStats),
+		helpFor("back", k.Back),
+	}))
+}
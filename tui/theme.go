@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomswokowski/shippost/config"
+)
+
+// Built-in theme names
+const (
+	themeDefault      = "default"
+	themeHighContrast = "high-contrast"
+	themeMono         = "mono"
+)
+
+// theme is a colour for every slot the styles are built from
+type theme struct {
+	Title        lipgloss.TerminalColor
+	Tagline      lipgloss.TerminalColor
+	Subtitle     lipgloss.TerminalColor
+	Text         lipgloss.TerminalColor
+	Dim          lipgloss.TerminalColor
+	Dimmer       lipgloss.TerminalColor
+	Selected     lipgloss.TerminalColor
+	SelectedDesc lipgloss.TerminalColor
+	Bullet       lipgloss.TerminalColor
+	Border       lipgloss.TerminalColor
+	BorderActive lipgloss.TerminalColor
+	HelpKey      lipgloss.TerminalColor
+	HelpKeyBg    lipgloss.TerminalColor
+	Success      lipgloss.TerminalColor
+	Error        lipgloss.TerminalColor
+	Warning      lipgloss.TerminalColor
+	URL          lipgloss.TerminalColor
+	MediaTag     lipgloss.TerminalColor
+	MediaTagBg   lipgloss.TerminalColor
+	ThreadNum    lipgloss.TerminalColor
+	ThreadNumBg  lipgloss.TerminalColor
+	CommitHash   lipgloss.TerminalColor
+	CommitTime   lipgloss.TerminalColor
+	AITag        lipgloss.TerminalColor
+	AITagBg      lipgloss.TerminalColor
+	InputLabel   lipgloss.TerminalColor
+	DisabledBg   lipgloss.TerminalColor
+}
+
+// slots maps the colour names used in theme files to the theme fields
+func (t *theme) slots() map[string]*lipgloss.TerminalColor {
+	return map[string]*lipgloss.TerminalColor{
+		"title":         &t.Title,
+		"tagline":       &t.Tagline,
+		"subtitle":      &t.Subtitle,
+		"text":          &t.Text,
+		"dim":           &t.Dim,
+		"dimmer":        &t.Dimmer,
+		"selected":      &t.Selected,
+		"selected_desc": &t.SelectedDesc,
+		"bullet":        &t.Bullet,
+		"border":        &t.Border,
+		"border_active": &t.BorderActive,
+		"help_key":      &t.HelpKey,
+		"help_key_bg":   &t.HelpKeyBg,
+		"success":       &t.Success,
+		"error":         &t.Error,
+		"warning":       &t.Warning,
+		"url":           &t.URL,
+		"media_tag":     &t.MediaTag,
+		"media_tag_bg":  &t.MediaTagBg,
+		"thread_num":    &t.ThreadNum,
+		"thread_num_bg": &t.ThreadNumBg,
+		"commit_hash":   &t.CommitHash,
+		"commit_time":   &t.CommitTime,
+		"ai_tag":        &t.AITag,
+		"ai_tag_bg":     &t.AITagBg,
+		"input_label":   &t.InputLabel,
+		"disabled_bg":   &t.DisabledBg,
+	}
+}
+
+// themes are the built-in themes by name
+var themes = map[string]func() theme{
+	themeDefault:      defaultTheme,
+	themeHighContrast: highContrastTheme,
+	themeMono:         monoTheme,
+}
+
+// defaultTheme adapts to light or dark terminal backgrounds
+func defaultTheme() theme {
+	return theme{
+		Title:        lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FF6B6B"},
+		Tagline:      lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#6B7280"},
+		Subtitle:     lipgloss.AdaptiveColor{Light: "#1F2937", Dark: "#E2E8F0"},
+		Text:         lipgloss.AdaptiveColor{Light: "#1F2937", Dark: "#E2E8F0"},
+		Dim:          lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#64748B"},
+		Dimmer:       lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#475569"},
+		Selected:     lipgloss.AdaptiveColor{Light: "#B45309", Dark: "#FFE66D"},
+		SelectedDesc: lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"},
+		Bullet:       lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FF6B6B"},
+		Border:       lipgloss.AdaptiveColor{Light: "#D1D5DB", Dark: "#334155"},
+		BorderActive: lipgloss.AdaptiveColor{Light: "#0D9488", Dark: "#4ECDC4"},
+		HelpKey:      lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#0C4A6E"},
+		HelpKeyBg:    lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#0EA5E9"},
+		Success:      lipgloss.AdaptiveColor{Light: "#059669", Dark: "#10B981"},
+		Error:        lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#EF4444"},
+		Warning:      lipgloss.AdaptiveColor{Light: "#D97706", Dark: "#F59E0B"},
+		URL:          lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"},
+		MediaTag:     lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#10B981"},
+		MediaTagBg:   lipgloss.AdaptiveColor{Light: "#059669", Dark: "#064E3B"},
+		ThreadNum:    lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#6366F1"},
+		ThreadNumBg:  lipgloss.AdaptiveColor{Light: "#4F46E5", Dark: "#312E81"},
+		CommitHash:   lipgloss.AdaptiveColor{Light: "#059669", Dark: "#95E6CB"},
+		CommitTime:   lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"},
+		AITag:        lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#F472B6"},
+		AITagBg:      lipgloss.AdaptiveColor{Light: "#DB2777", Dark: "#831843"},
+		InputLabel:   lipgloss.AdaptiveColor{Light: "#4B5563", Dark: "#94A3B8"},
+		DisabledBg:   lipgloss.AdaptiveColor{Light: "#F3F4F6", Dark: "#1E293B"},
+	}
+}
+
+// highContrastTheme uses pure black or white text and saturated accents,
+// with no mid-grey dim text
+func highContrastTheme() theme {
+	fg := lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"}
+	bg := lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"}
+	accent := lipgloss.AdaptiveColor{Light: "#0000CC", Dark: "#FFFF00"}
+	return theme{
+		Title:        accent,
+		Tagline:      fg,
+		Subtitle:     fg,
+		Text:         fg,
+		Dim:          fg,
+		Dimmer:       lipgloss.AdaptiveColor{Light: "#333333", Dark: "#CCCCCC"},
+		Selected:     accent,
+		SelectedDesc: accent,
+		Bullet:       accent,
+		Border:       fg,
+		BorderActive: accent,
+		HelpKey:      bg,
+		HelpKeyBg:    fg,
+		Success:      lipgloss.AdaptiveColor{Light: "#006600", Dark: "#00FF00"},
+		Error:        lipgloss.AdaptiveColor{Light: "#CC0000", Dark: "#FF5555"},
+		Warning:      lipgloss.AdaptiveColor{Light: "#994400", Dark: "#FFAA00"},
+		URL:          lipgloss.AdaptiveColor{Light: "#0000CC", Dark: "#00FFFF"},
+		MediaTag:     bg,
+		MediaTagBg:   fg,
+		ThreadNum:    bg,
+		ThreadNumBg:  fg,
+		CommitHash:   lipgloss.AdaptiveColor{Light: "#006600", Dark: "#00FF00"},
+		CommitTime:   fg,
+		AITag:        bg,
+		AITagBg:      accent,
+		InputLabel:   fg,
+		DisabledBg:   bg,
+	}
+}
+
+// monoTheme sets no colours at all; emphasis comes from bold, underline and
+// the ▸ cursor
+func monoTheme() theme {
+	var t theme
+	for _, slot := range t.slots() {
+		*slot = lipgloss.NoColor{}
+	}
+	return t
+}
+
+// colourPattern matches the colours lipgloss accepts: hex or an ANSI number
+var colourPattern = regexp.MustCompile(`^(#[0-9A-Fa-f]{6}|#[0-9A-Fa-f]{3}|[0-9]{1,3})$`)
+
+// loadTheme returns the colours of the configured theme: a built-in one,
+// or a theme file overriding slots of its base. NO_COLOR forces mono.
+func loadTheme(name string) (theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return monoTheme(), nil
+	}
+	if name == "" {
+		name = themeDefault
+	}
+	if builtin, ok := themes[name]; ok {
+		return builtin(), nil
+	}
+
+	file, err := config.LoadTheme(name)
+	if err != nil {
+		return defaultTheme(), err
+	}
+	if file == nil {
+		return defaultTheme(), fmt.Errorf("unknown theme %q (use %s, or add themes/%s.json to the config directory)", name, strings.Join(themeNames(), ", "), name)
+	}
+
+	base := themes[themeDefault]
+	if file.Base != "" {
+		if base = themes[file.Base]; base == nil {
+			return defaultTheme(), fmt.Errorf("theme %s: unknown base theme %q", name, file.Base)
+		}
+	}
+
+	t := base()
+	slots := t.slots()
+	for slot, c := range file.Colors {
+		field, ok := slots[slot]
+		if !ok {
+			return defaultTheme(), fmt.Errorf("theme %s: unknown colour %q", name, slot)
+		}
+		for _, v := range []string{c.Light, c.Dark} {
+			if !colourPattern.MatchString(v) {
+				return defaultTheme(), fmt.Errorf("theme %s: invalid colour %q for %s (use #RRGGBB or an ANSI number)", name, v, slot)
+			}
+		}
+		*field = lipgloss.AdaptiveColor{Light: c.Light, Dark: c.Dark}
+	}
+	return t, nil
+}
+
+// themeNames lists the built-in themes
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
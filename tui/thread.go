@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxMediaPerPost is the number of images X allows on a single post
+const maxMediaPerPost = 4
+
+// Thread editing operations shared by the compose and outline views.
+// Each keeps the textarea in sync with the current post.
+
+// syncCurrentPost saves the textarea contents into the current thread item
+func (m *Model) syncCurrentPost() {
+	m.thread[m.currentPost].text = m.textarea.Value()
+}
+
+// loadCurrentPost shows the current thread item in the textarea
+func (m *Model) loadCurrentPost() {
+	m.textarea.SetValue(m.thread[m.currentPost].text)
+}
+
+// movePost swaps the current post with its neighbour delta positions away
+func (m *Model) movePost(delta int) {
+	target := m.currentPost + delta
+	if target < 0 || target >= len(m.thread) {
+		return
+	}
+	m.thread[m.currentPost], m.thread[target] = m.thread[target], m.thread[m.currentPost]
+	m.threadVersion++
+	m.currentPost = target
+	m.loadCurrentPost()
+}
+
+// insertPost adds an empty post before (offset 0) or after (offset 1) the current one
+func (m *Model) insertPost(offset int) {
+	at := m.currentPost + offset
+	m.thread = append(m.thread[:at], append([]threadItem{{text: "", media: nil}}, m.thread[at:]...)...)
+	m.threadVersion++
+	m.currentPost = at
+	m.loadCurrentPost()
+}
+
+// duplicatePost inserts a copy of the current post, including its media, after it
+func (m *Model) duplicatePost() {
+	item := m.thread[m.currentPost]
+	dup := threadItem{
+		text:  item.text,
+		media: append([]string(nil), item.media...),
+		poll:  clonePoll(item.poll),
+	}
+	at := m.currentPost + 1
+	m.thread = append(m.thread[:at], append([]threadItem{dup}, m.thread[at:]...)...)
+	m.threadVersion++
+	m.currentPost = at
+	m.loadCurrentPost()
+}
+
+// attachError reports why no more media can be attached to the current post, if anything
+func (m Model) attachError() error {
+	item := m.thread[m.currentPost]
+	if item.poll != nil {
+		return fmt.Errorf("a post can't have both a poll and media")
+	}
+	if len(item.media) >= maxMediaPerPost {
+		return fmt.Errorf("maximum %d images per post", maxMediaPerPost)
+	}
+	return nil
+}
+
+// finishAttaching adds an image pasted or rendered in the background to the
+// post it was started from. The thread may have changed meanwhile, so the
+// post is checked again before anything is attached.
+func (m Model) finishAttaching(post, version int, path string, err error) (tea.Model, tea.Cmd) {
+	m.attaching--
+	if m.attaching <= 0 {
+		m.attaching = 0
+		m.status = ""
+	}
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.tempFiles = append(m.tempFiles, path)
+
+	switch {
+	case version != m.threadVersion || post >= len(m.thread):
+		m.err = fmt.Errorf("the posts changed while the image was prepared - attach it again")
+	case m.thread[post].poll != nil:
+		m.err = fmt.Errorf("a post can't have both a poll and media")
+	case len(m.thread[post].media) >= maxMediaPerPost:
+		m.err = fmt.Errorf("maximum %d images per post", maxMediaPerPost)
+	default:
+		m.thread[post].media = append(m.thread[post].media, path)
+		m.err = nil
+	}
+	return m, nil
+}
+
+// deletePost removes the current post, keeping at least one post in the thread
+func (m *Model) deletePost() {
+	if len(m.thread) <= 1 {
+		return
+	}
+	m.thread = append(m.thread[:m.currentPost], m.thread[m.currentPost+1:]...)
+	m.threadVersion++
+	if m.currentPost >= len(m.thread) {
+		m.currentPost = len(m.thread) - 1
+	}
+	m.loadCurrentPost()
+}
+
+// mergeWithNext joins the current post and the one after it into a single post
+func (m *Model) mergeWithNext() error {
+	if m.currentPost >= len(m.thread)-1 {
+		return fmt.Errorf("no next post to merge with")
+	}
+	cur, next := m.thread[m.currentPost], m.thread[m.currentPost+1]
+	if len(cur.media)+len(next.media) > maxMediaPerPost {
+		return fmt.Errorf("merged post would have more than %d images", maxMediaPerPost)
+	}
+	if cur.poll != nil && next.poll != nil {
+		return fmt.Errorf("both posts have a poll")
+	}
+	poll := cur.poll
+	if poll == nil {
+		poll = next.poll
+	}
+	if poll != nil && len(cur.media)+len(next.media) > 0 {
+		return fmt.Errorf("merged post would have both a poll and media")
+	}
+
+	var parts []string
+	for _, text := range []string{cur.text, next.text} {
+		if t := strings.TrimSpace(text); t != "" {
+			parts = append(parts, t)
+		}
+	}
+	merged := threadItem{
+		text:  strings.Join(parts, "\n\n"),
+		media: append(append([]string(nil), cur.media...), next.media...),
+		poll:  poll,
+	}
+
+	m.thread[m.currentPost] = merged
+	m.thread = append(m.thread[:m.currentPost+1], m.thread[m.currentPost+2:]...)
+	m.threadVersion++
+	m.loadCurrentPost()
+	return nil
+}
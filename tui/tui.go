@@ -1,17 +1,23 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/tomswokowski/shippost/ai"
 	"github.com/tomswokowski/shippost/config"
 	"github.com/tomswokowski/shippost/git"
+	"github.com/tomswokowski/shippost/history"
+	"github.com/tomswokowski/shippost/hook"
 	"github.com/tomswokowski/shippost/x"
 )
 
@@ -28,6 +34,12 @@ const (
 	stateAskInput
 	stateGenerating
 	stateSmartCompose
+	stateThreadOutline
+	stateCodeSnippet
+	statePollEditor
+	stateReferenceInput
+	stateContinueThread
+	stateStats
 )
 
 type menuItem struct {
@@ -37,9 +49,10 @@ type menuItem struct {
 }
 
 type threadItem struct {
-	text     string
-	mediaIDs []string
-	media    []string
+	text        string
+	media       []string // local paths, uploaded when the thread is posted
+	poll        *x.Poll
+	duplicateOf string // published post this AI suggestion closely resembles
 }
 
 // Model is the main TUI model
@@ -47,55 +60,98 @@ type Model struct {
 	state              state
 	menuCursor         int
 	menuItems          []menuItem
+	keys               keyMap
+	showHelp           bool // full help overlay is open
 	textarea           textarea.Model
-	pathInput          textinput.Model
+	picker             filePicker
+	snippets           snippetPicker
+	pollEditor         pollEditor
+	threadPicker       threadPicker
+	stats              statsView
+	reference          *reference // post being replied to or quoted, if any
+	referenceInput     textinput.Model
+	referenceQuote     bool     // quote rather than reply to the entered post
+	attaching          int      // pasted or rendered images still being prepared
+	uploads            []upload // media being uploaded while posting
+	tempFiles          []string // files created for pasted images, removed on exit
+	previews           map[string]*mediaPreview
+	kitty              *kittyImages
 	askInput           textarea.Model
 	commitPromptInput  textarea.Model
 	thread             []threadItem
 	currentPost        int
+	threadVersion      int // incremented when posts are added, removed or reordered, so late attachments can tell
 	status             string
 	err                error
 	postURL            string
 	postURLs           []string
+	postIDs            []string // just-published posts, for undo
+	confirmUndo        bool
+	deleting           bool
 	width              int
 	height             int
-	xClient            *x.Client
+	xClient            x.API
 	cfg                *config.Config
 	commits            []git.Commit
+	repos              []git.Repo // repositories Smart Post reads commits from
+	skippedRepos       []error    // configured repositories that failed to open
+	commitLog          git.Log    // where the next page of commits starts
+	commitLoad         int        // incremented when the commits are reloaded so stale pages are ignored
+	loadingMoreCommits bool
+	hookQueue          []hook.Entry // shippable commits and tags the git hooks queued
+	hookPrompt         bool         // the home screen offers a post about hookQueue
+	pickHookCommits    bool         // select hookQueue once the commits load
 	commitCursor       int
 	selectedCommits    []int
+	draftCommits       []git.Commit // commits the Smart Post draft was written from; nil for Ask
 	aiSuggestion       string
 	isSmartPost        bool
 	smartMenuCursor    int
 	askQuery           string
 	commitPromptActive bool
 	commitScrollOffset int
-	commitSearch       string
-	commitSearchActive bool
+	commitSearch       textinput.Model
+	commitSearchErr    error         // invalid qualifier in the search
+	commitHighlights   map[int][]int // matched subject runes by commit index
 	filteredCommits    []int
+	commitDetail       commitDetail
 	allowThread        bool
 	inGitRepo          bool
+	pastPosts          []string
+	generation         int                // incremented per AI run so stale results are ignored
+	generationReturn   state              // state to return to if generation fails or is cancelled
+	generationPreview  string             // partial AI response streamed while generating
+	cancelGeneration   context.CancelFunc // stops the running AI generation
 }
 
 // New creates a new TUI model
-func New() (Model, error) {
+func New(repoPaths []string) (Model, error) {
 	cfg, err := config.Load()
+	if err != nil {
+		// The fake API needs no credentials
+		if !x.FakeEnabled() {
+			return Model{}, err
+		}
+		cfg = &config.Config{}
+	}
+
+	keys, err := loadKeyMap()
 	if err != nil {
 		return Model{}, err
 	}
+	colours, err := loadTheme(cfg.Theme)
+	if err != nil {
+		return Model{}, err
+	}
+	initStyles(colours)
 
 	ta := textarea.New()
 	ta.Placeholder = "What's happening?"
-	ta.CharLimit = 280
+	ta.CharLimit = 0 // no hard limit - long text can be split into a thread
 	ta.SetWidth(60)
 	ta.SetHeight(5)
 	ta.ShowLineNumbers = false
 
-	pi := textinput.New()
-	pi.Placeholder = "~/Pictures/screenshot.png"
-	pi.Width = 50
-	pi.CharLimit = 256
-
 	askIn := textarea.New()
 	askIn.Placeholder = "What did I accomplish today?"
 	askIn.SetWidth(55)
@@ -110,8 +166,27 @@ func New() (Model, error) {
 	commitPrompt.CharLimit = 500
 	commitPrompt.ShowLineNumbers = false
 
+	commitSearch := textinput.New()
+	commitSearch.Prompt = "/"
+	commitSearch.Placeholder = "text, author:name, file:path, type:feat, since:2d"
+	commitSearch.CharLimit = 200
+	commitSearch.PromptStyle = dimStyle
+	commitSearch.TextStyle = selectedStyle
+
+	// Past posts are optional style examples - ignore a missing or broken history
+	pastEntries, _ := history.Load()
+
+	backend, err := git.ParseBackend(cfg.GitBackend)
+	if err != nil {
+		return Model{}, err
+	}
+	repos, skippedRepos, err := loadRepos(repoPaths, cfg.Repos, backend)
+	if err != nil {
+		return Model{}, err
+	}
+
 	claudeAvailable := ai.IsClaudeAvailable()
-	inGitRepo := git.IsGitRepo()
+	inGitRepo := len(repos) > 0
 
 	smartPostEnabled := claudeAvailable && inGitRepo
 	smartPostDesc := "AI-powered posts from your git commits"
@@ -121,6 +196,16 @@ func New() (Model, error) {
 		smartPostDesc = "Requires Claude Code CLI (not installed)"
 	}
 
+	// Offer a post about what the git hooks queued since the last launch
+	var hookQueue []hook.Entry
+	if smartPostEnabled {
+		dirs := make([]string, len(repos))
+		for i, r := range repos {
+			dirs[i] = r.Dir
+		}
+		hookQueue = hook.Pending(dirs)
+	}
+
 	menuItems := []menuItem{
 		{
 			title:       "Quick Post",
@@ -132,25 +217,54 @@ func New() (Model, error) {
 			description: smartPostDesc,
 			enabled:     smartPostEnabled,
 		},
+		{
+			title:       "Reply/Quote",
+			description: "Reply to or quote an existing post",
+			enabled:     true,
+		},
+		{
+			title:       "Continue Thread",
+			description: "Add posts to a thread you already published",
+			enabled:     true,
+		},
+		{
+			title:       "Stats",
+			description: "Impressions, likes, reposts and replies of your posts",
+			enabled:     true,
+		},
 	}
 
 	return Model{
 		state:             stateHome,
 		menuCursor:        0,
 		menuItems:         menuItems,
+		keys:              keys,
 		textarea:          ta,
-		pathInput:         pi,
+		picker:            newFilePicker(),
+		snippets:          newSnippetPicker(),
+		pollEditor:        newPollEditor(),
+		referenceInput:    newReferenceInput(),
+		threadPicker:      newThreadPicker(),
+		previews:          make(map[string]*mediaPreview),
+		kitty:             newKittyImages(),
 		askInput:          askIn,
 		commitPromptInput: commitPrompt,
-		thread:            []threadItem{{text: "", mediaIDs: nil, media: nil}},
+		commitSearch:      commitSearch,
+		thread:            []threadItem{{text: "", media: nil}},
 		currentPost:       0,
-		xClient:           x.NewClient(cfg),
+		xClient:           x.New(cfg),
 		cfg:               cfg,
 		commits:           nil,
 		commitCursor:      0,
 		selectedCommits:   nil,
 		allowThread:       true,
 		inGitRepo:         inGitRepo,
+		repos:             repos,
+		skippedRepos:      skippedRepos,
+		commitLog:         git.NewLog(repos),
+		hookQueue:         hookQueue,
+		hookPrompt:        len(hookQueue) > 0,
+		pastPosts:         history.Texts(pastEntries),
 	}, nil
 }
 
@@ -158,11 +272,35 @@ func (m Model) Init() tea.Cmd {
 	return nil
 }
 
+// Update handles a message, then renders any image previews the new screen
+// needs and tracks the thumbnails it shows
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	after, ok := next.(Model)
+	if !ok {
+		return next, cmd
+	}
+	return after, tea.Batch(cmd, after.loadPreviews(), trackThumbnails(m, after))
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.showHelp {
+			if key.Matches(msg, m.keys.Quit) {
+				return m, tea.Quit
+			}
+			m.showHelp = false
+			return m, nil
+		}
+		// Printable help keys like "?" are text while typing
+		if key.Matches(msg, m.keys.Help) && (msg.Type != tea.KeyRunes || !m.acceptsText()) {
+			m.showHelp = true
+			return m, nil
+		}
+
 		switch m.state {
 		case stateHome:
 			return m.handleHomeKeys(msg)
@@ -172,41 +310,72 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleAskInputKeys(msg)
 		case stateCommitBrowser:
 			return m.handleCommitBrowserKeys(msg)
+		case stateGenerating:
+			return m.handleGeneratingKeys(msg)
 		case stateSmartCompose:
 			return m.handleComposeKeys(msg, true)
 		case stateCompose:
 			return m.handleComposeKeys(msg, false)
 		case stateMediaInput:
 			return m.handleMediaInputKeys(msg)
+		case stateThreadOutline:
+			return m.handleThreadOutlineKeys(msg)
+		case stateCodeSnippet:
+			return m.handleCodeSnippetKeys(msg)
+		case statePollEditor:
+			return m.handlePollEditorKeys(msg)
+		case stateReferenceInput:
+			return m.handleReferenceInputKeys(msg)
+		case stateContinueThread:
+			return m.handleContinueThreadKeys(msg)
+		case stateStats:
+			return m.handleStatsKeys(msg)
 		case statePosted:
 			return m.handlePostedKeys(msg)
 		}
 
 	case commitsLoadedMsg:
-		m.status = ""
-		if msg.err != nil {
-			m.err = msg.err
-			m.state = stateHome
-		} else {
-			m.commits = msg.commits
-			m.filteredCommits = make([]int, len(msg.commits))
-			for i := range msg.commits {
-				m.filteredCommits[i] = i
-			}
+		return m.handleCommitsLoaded(msg)
+
+	case commitDetailMsg:
+		return m.handleCommitDetailLoaded(msg)
+
+	case aiProgressMsg:
+		if msg.generation != m.generation {
+			return m, nil
+		}
+		m.generationPreview = msg.update.text
+		if msg.update.status != "" {
+			m.status = msg.update.status
 		}
+		return m, waitForProgress(msg.generation, msg.progress)
 
 	case aiSuggestionMsg:
+		if msg.generation != m.generation {
+			return m, nil
+		}
 		m.status = ""
+		m.cancelGeneration = nil
+		m.generationPreview = ""
 		if msg.err != nil {
 			m.err = msg.err
-			m.state = stateSmartMenu
+			m.state = m.generationReturn
+			if m.state == stateSmartCompose {
+				m.textarea.Focus()
+				return m, textarea.Blink
+			}
 		} else {
 			m.thread = nil
+			m.threadVersion++
 			for _, suggestion := range msg.suggestions {
-				m.thread = append(m.thread, threadItem{text: suggestion, mediaIDs: nil, media: nil})
+				item := threadItem{text: suggestion, media: nil}
+				if dup, ok := ai.FindDuplicate(suggestion, m.pastPosts); ok {
+					item.duplicateOf = dup.Text
+				}
+				m.thread = append(m.thread, item)
 			}
 			if len(m.thread) == 0 {
-				m.thread = []threadItem{{text: "", mediaIDs: nil, media: nil}}
+				m.thread = []threadItem{{text: "", media: nil}}
 			}
 			m.state = stateSmartCompose
 			m.currentPost = 0
@@ -215,25 +384,55 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, textarea.Blink
 		}
 
-	case mediaUploadMsg:
-		if msg.err != nil {
-			m.err = msg.err
-			m.status = ""
+	case uploadProgressMsg:
+		if msg.index < len(m.uploads) {
+			m.uploads[msg.index].state = msg.state
+			m.uploads[msg.index].err = msg.err
+		}
+		if done := m.uploadsDone(); done == len(m.uploads) {
+			m.status = "Posting..."
 		} else {
-			m.thread[m.currentPost].mediaIDs = append(m.thread[m.currentPost].mediaIDs, msg.mediaID)
-			m.thread[m.currentPost].media = append(m.thread[m.currentPost].media, msg.path)
-			m.status = ""
-			m.err = nil
+			m.status = fmt.Sprintf("Uploading media (%d/%d)...", done, len(m.uploads))
 		}
-		if m.isSmartPost {
-			m.state = stateSmartCompose
+		return m, waitForUploadProgress(msg.progress)
+
+	case clipboardImageMsg:
+		return m.finishAttaching(msg.post, msg.version, msg.path, msg.err)
+
+	case previewRenderedMsg:
+		m.previews[msg.path] = msg.preview
+		return m, nil
+
+	case kittyUploadedMsg:
+		m.kittyUploaded(msg.id)
+		return m, nil
+
+	case postFetchedMsg:
+		return m.handlePostFetched(msg)
+
+	case hunksLoadedMsg:
+		m.snippets.loading = false
+		m.snippets.hunks = msg.hunks
+		m.snippets.err = msg.err
+		return m, nil
+
+	case snippetRenderedMsg:
+		return m.finishAttaching(msg.post, msg.version, msg.path, msg.err)
+
+	case editorFinishedMsg:
+		if msg.path != "" {
+			defer os.Remove(msg.path)
+		}
+		if msg.err != nil {
+			m.err = fmt.Errorf("editor: %w", msg.err)
 		} else {
-			m.state = stateCompose
+			m.err = m.applyEditedThread(msg.path)
 		}
 		m.textarea.Focus()
 		return m, textarea.Blink
 
 	case postResultMsg:
+		m.uploads = nil
 		if msg.err != nil {
 			m.err = msg.err
 			if m.isSmartPost {
@@ -244,18 +443,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.status = ""
 		} else {
 			m.state = statePosted
+			m.postIDs = msg.ids
 			m.postURLs = msg.urls
+			m.confirmUndo = false
 			if len(msg.urls) > 0 {
 				m.postURL = msg.urls[0]
 			}
 			m.status = "Posted successfully!"
-			m.err = nil
+			m.err = m.forgetPublishedCommits()
+			if !x.IsFake(m.xClient) {
+				for _, item := range m.thread {
+					if strings.TrimSpace(item.text) != "" {
+						m.pastPosts = append(m.pastPosts, item.text)
+					}
+				}
+			}
 		}
 
+	case deleteResultMsg:
+		return m.handleDeleteResult(msg)
+
+	case statsLoadedMsg:
+		return m.handleStatsLoaded(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.textarea.SetWidth(min(60, msg.Width-4))
+		m.resize()
+		if m.state == stateCommitBrowser {
+			return m, tea.Batch(tea.ClearScreen, m.syncCommitDetail())
+		}
 		return m, tea.ClearScreen
 	}
 
@@ -265,24 +482,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // Key handlers for each state
 
 func (m Model) handleHomeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "ctrl+c", "esc":
+	if m.hookPrompt {
+		return m.handleHookPromptKeys(msg)
+	}
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit, k.Close, k.Back):
 		return m, tea.Quit
-	case "up", "k":
+	case key.Matches(msg, k.Up):
 		if m.menuCursor > 0 {
 			m.menuCursor--
 		}
-	case "down", "j":
+	case key.Matches(msg, k.Down):
 		if m.menuCursor < len(m.menuItems)-1 {
 			m.menuCursor++
 		}
-	case "enter":
+	case key.Matches(msg, k.Select):
 		item := m.menuItems[m.menuCursor]
 		if item.enabled {
+			m.reference = nil
 			if m.menuCursor == 0 {
 				m.state = stateCompose
 				m.isSmartPost = false
-				m.thread = []threadItem{{text: "", mediaIDs: nil, media: nil}}
+				m.thread = []threadItem{{text: "", media: nil}}
+				m.threadVersion++
 				m.currentPost = 0
 				m.textarea.SetValue("")
 				m.textarea.Focus()
@@ -293,6 +516,16 @@ func (m Model) handleHomeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.smartMenuCursor = 0
 				m.err = nil
 				return m, nil
+			} else if m.menuCursor == 2 {
+				m.state = stateReferenceInput
+				m.isSmartPost = false
+				m.referenceInput.SetValue("")
+				m.err = nil
+				return m, m.referenceInput.Focus()
+			} else if m.menuCursor == 3 {
+				return m.openThreadPicker()
+			} else if m.menuCursor == 4 {
+				return m.openStats()
 			}
 		}
 	}
@@ -300,65 +533,76 @@ func (m Model) handleHomeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleSmartMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc", "q":
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Back, k.Close):
 		m.state = stateHome
 		m.err = nil
 		return m, nil
-	case "up", "k":
+	case key.Matches(msg, k.Up):
 		if m.smartMenuCursor > 0 {
 			m.smartMenuCursor--
 		}
-	case "down", "j":
+	case key.Matches(msg, k.Down):
 		if m.smartMenuCursor < 1 {
 			m.smartMenuCursor++
 		}
-	case "enter":
+	case key.Matches(msg, k.Select):
 		if m.smartMenuCursor == 0 {
-			m.state = stateCommitBrowser
-			m.commitCursor = 0
-			m.selectedCommits = nil
-			m.commitPromptInput.SetValue("")
-			m.commitPromptActive = false
-			m.commitScrollOffset = 0
-			m.commitSearch = ""
-			m.commitSearchActive = false
-			m.filteredCommits = nil
-			m.status = "Loading commits..."
+			m.openCommitBrowser()
 			return m, m.loadCommits()
 		} else {
 			m.state = stateAskInput
 			m.askInput.SetValue("")
 			m.askInput.Focus()
+			m.resetCommits()
 			m.status = "Loading commits..."
 			return m, tea.Batch(textarea.Blink, m.loadCommits())
 		}
-	case "ctrl+c":
+	case key.Matches(msg, k.Quit):
 		return m, tea.Quit
 	}
 	return m, nil
 }
 
+// openCommitBrowser shows the commit browser with nothing selected and
+// starts over from the newest commits; the caller loads them
+func (m *Model) openCommitBrowser() {
+	m.state = stateCommitBrowser
+	m.commitCursor = 0
+	m.selectedCommits = nil
+	m.commitPromptInput.SetValue("")
+	m.commitPromptActive = false
+	m.commitScrollOffset = 0
+	m.commitSearch.SetValue("")
+	m.commitSearch.Blur()
+	m.filteredCommits = nil
+	m.commitDetail = commitDetail{}
+	m.resetCommits()
+	m.askQuery = ""
+	m.status = "Loading commits..."
+}
+
 func (m Model) handleAskInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Back):
 		m.state = stateSmartMenu
 		m.askInput.Blur()
 		m.err = nil
 		return m, nil
-	case "ctrl+enter", "ctrl+g":
+	case key.Matches(msg, k.Generate):
 		query := m.askInput.Value()
 		if query == "" {
 			m.err = fmt.Errorf("please enter a query")
 			return m, nil
 		}
 		m.askQuery = query
-		m.state = stateGenerating
-		m.status = "Claude is thinking..."
-		return m, m.generateFromQuery()
-	case "ctrl+c":
+		cmd := m.generateFromQuery("Claude is thinking...")
+		return m, cmd
+	case key.Matches(msg, k.Quit):
 		return m, tea.Quit
-	case "ctrl+t":
+	case key.Matches(msg, k.ToggleThread):
 		m.allowThread = !m.allowThread
 		return m, nil
 	}
@@ -368,116 +612,60 @@ func (m Model) handleAskInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleCommitBrowserKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	const maxVisible = 5
+	if m.commitSearch.Focused() {
+		return m.handleCommitSearchKeys(msg)
+	}
+	if m.commitPromptActive {
+		return m.handleCommitPromptKeys(msg)
+	}
 
-	switch msg.String() {
-	case "esc":
-		if m.commitSearchActive {
-			m.commitSearchActive = false
-			m.commitSearch = ""
-			m.filterCommits()
-			m.commitCursor = 0
-			m.commitScrollOffset = 0
-			return m, nil
-		}
-		if m.commitPromptActive {
-			m.commitPromptActive = false
-			m.commitPromptInput.Blur()
-			return m, nil
-		}
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back, k.Close) && m.commitDetail.open && !m.wide():
+		m.commitDetail.open = false
+	case key.Matches(msg, k.Back, k.Close):
 		m.state = stateSmartMenu
-		m.commits = nil
+		m.resetCommits()
 		m.selectedCommits = nil
 		m.err = nil
 		return m, nil
-	case "q":
-		if m.commitSearchActive || m.commitPromptActive {
-			// Pass to input
-		} else {
-			m.state = stateSmartMenu
-			m.commits = nil
-			m.selectedCommits = nil
-			m.err = nil
-			return m, nil
-		}
-	case "/":
-		if m.commitPromptActive {
-			var cmd tea.Cmd
-			m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
-			return m, cmd
-		}
-		if m.commitSearchActive {
-			m.commitSearch += "/"
-			m.filterCommits()
-			m.commitCursor = 0
-			m.commitScrollOffset = 0
-			return m, nil
-		}
-		if m.commitSearch != "" {
-			m.commitSearch = ""
+	case key.Matches(msg, k.Details):
+		m.commitDetail.open = !m.commitDetail.open
+	case key.Matches(msg, k.ScrollUp):
+		_, height := m.detailSize()
+		m.scrollCommitDetail(-max(1, height/2))
+	case key.Matches(msg, k.ScrollDown):
+		_, height := m.detailSize()
+		m.scrollCommitDetail(max(1, height/2))
+	case key.Matches(msg, k.Search):
+		if m.commitSearch.Value() != "" {
+			m.commitSearch.SetValue("")
 			m.filterCommits()
 			m.commitCursor = 0
 			m.commitScrollOffset = 0
 		} else {
-			m.commitSearchActive = true
-		}
-		return m, nil
-	case "tab":
-		if m.commitSearchActive {
-			m.commitSearchActive = false
-			return m, nil
-		}
-		m.commitPromptActive = !m.commitPromptActive
-		if m.commitPromptActive {
-			m.commitPromptInput.Focus()
-			return m, textarea.Blink
-		} else {
-			m.commitPromptInput.Blur()
-			return m, nil
-		}
-	case "up", "k":
-		if m.commitPromptActive {
-			var cmd tea.Cmd
-			m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
+			cmd := m.commitSearch.Focus()
 			return m, cmd
 		}
-		if m.commitSearchActive {
-			break
-		}
+	case key.Matches(msg, k.FocusPrompt):
+		m.commitPromptActive = true
+		m.commitPromptInput.Focus()
+		return m, textarea.Blink
+	case key.Matches(msg, k.Up):
 		if m.commitCursor > 0 {
 			m.commitCursor--
-			if m.commitCursor < m.commitScrollOffset {
-				m.commitScrollOffset = m.commitCursor
-			}
-		}
-	case "down", "j":
-		if m.commitPromptActive {
-			var cmd tea.Cmd
-			m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
-			return m, cmd
-		}
-		if m.commitSearchActive {
-			break
+			m.scrollCommits()
 		}
+	case key.Matches(msg, k.Down):
 		if m.commitCursor < len(m.filteredCommits)-1 {
 			m.commitCursor++
-			if m.commitCursor >= m.commitScrollOffset+maxVisible {
-				m.commitScrollOffset = m.commitCursor - maxVisible + 1
-			}
-		}
-	case "a":
-		if m.commitSearchActive {
-			m.commitSearch += "a"
-			m.filterCommits()
-			m.commitCursor = 0
-			m.commitScrollOffset = 0
-			return m, nil
-		}
-		if m.commitPromptActive {
-			var cmd tea.Cmd
-			m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
-			return m, cmd
+			m.scrollCommits()
 		}
+		cmd := tea.Batch(m.syncCommitDetail(), m.loadMoreCommits())
+		return m, cmd
+	case key.Matches(msg, k.SelectAll):
 		// Toggle select all filtered commits
 		if len(m.selectedCommits) == len(m.filteredCommits) {
 			m.selectedCommits = nil
@@ -485,184 +673,296 @@ func (m Model) handleCommitBrowserKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selectedCommits = make([]int, len(m.filteredCommits))
 			copy(m.selectedCommits, m.filteredCommits)
 		}
+	case key.Matches(msg, k.ToggleCommit):
+		m.toggleCommit()
+	case key.Matches(msg, k.Select, k.Generate):
+		return m.generateFromCommits()
+	case key.Matches(msg, k.ToggleThread):
+		m.allowThread = !m.allowThread
+	}
+	cmd := m.syncCommitDetail()
+	return m, cmd
+}
+
+// handleCommitSearchKeys edits the commit search while it has focus. Every
+// printable key is typed into the search; the arrows still move through the
+// results.
+func (m Model) handleCommitSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back):
+		m.commitSearch.SetValue("")
+		m.commitSearch.Blur()
+	case key.Matches(msg, k.Select, k.FocusPrompt):
+		m.commitSearch.Blur()
 		return m, nil
-	case " ":
-		if m.commitSearchActive {
-			m.commitSearch += " "
-			m.filterCommits()
-			return m, nil
+	case key.Matches(msg, k.FilterUp, k.FilterDown):
+		if key.Matches(msg, k.FilterUp) && m.commitCursor > 0 {
+			m.commitCursor--
+		} else if key.Matches(msg, k.FilterDown) && m.commitCursor < len(m.filteredCommits)-1 {
+			m.commitCursor++
 		}
-		if m.commitPromptActive {
-			var cmd tea.Cmd
-			m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
+		m.scrollCommits()
+		cmd := tea.Batch(m.syncCommitDetail(), m.loadMoreCommits())
+		return m, cmd
+	default:
+		before := m.commitSearch.Value()
+		var cmd tea.Cmd
+		m.commitSearch, cmd = m.commitSearch.Update(msg)
+		if m.commitSearch.Value() == before {
 			return m, cmd
 		}
-		if m.commitCursor < len(m.filteredCommits) {
-			realIdx := m.filteredCommits[m.commitCursor]
-			found := false
-			for i, s := range m.selectedCommits {
-				if s == realIdx {
-					m.selectedCommits = append(m.selectedCommits[:i], m.selectedCommits[i+1:]...)
-					found = true
-					break
-				}
-			}
-			if !found {
-				m.selectedCommits = append(m.selectedCommits, realIdx)
-			}
-		}
-	case "enter", "ctrl+g":
-		if m.commitSearchActive {
-			m.commitSearchActive = false
-			return m, nil
-		}
-		if m.commitPromptActive {
-			m.commitPromptActive = false
-			m.commitPromptInput.Blur()
-		}
-		if len(m.selectedCommits) == 0 && m.commitCursor < len(m.filteredCommits) {
-			m.selectedCommits = []int{m.filteredCommits[m.commitCursor]}
-		}
-		m.state = stateGenerating
-		m.status = "Generating suggestion..."
-		return m, m.generateSuggestion()
-	case "ctrl+c":
+		m.filterCommits()
+		m.commitCursor = 0
+		m.commitScrollOffset = 0
+		return m, tea.Batch(cmd, m.syncCommitDetail())
+	}
+	m.filterCommits()
+	m.commitCursor = 0
+	m.commitScrollOffset = 0
+	cmd := m.syncCommitDetail()
+	return m, cmd
+}
+
+// handleCommitPromptKeys edits the optional prompt while it has focus
+func (m Model) handleCommitPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
 		return m, tea.Quit
-	case "ctrl+t":
+	case key.Matches(msg, k.Back, k.FocusPrompt):
+		m.commitPromptActive = false
+		m.commitPromptInput.Blur()
+		return m, nil
+	case key.Matches(msg, k.Select, k.Generate):
+		m.commitPromptActive = false
+		m.commitPromptInput.Blur()
+		return m.generateFromCommits()
+	case key.Matches(msg, k.ToggleThread):
 		m.allowThread = !m.allowThread
 		return m, nil
-	case "backspace":
-		if m.commitSearchActive && len(m.commitSearch) > 0 {
-			m.commitSearch = m.commitSearch[:len(m.commitSearch)-1]
-			m.filterCommits()
-			m.commitCursor = 0
-			m.commitScrollOffset = 0
-			return m, nil
+	}
+	var cmd tea.Cmd
+	m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
+	return m, cmd
+}
+
+// toggleCommit selects or deselects the commit under the cursor
+func (m *Model) toggleCommit() {
+	if m.commitCursor >= len(m.filteredCommits) {
+		return
+	}
+	realIdx := m.filteredCommits[m.commitCursor]
+	for i, s := range m.selectedCommits {
+		if s == realIdx {
+			m.selectedCommits = append(m.selectedCommits[:i], m.selectedCommits[i+1:]...)
+			return
 		}
-		if m.commitPromptActive {
-			var cmd tea.Cmd
-			m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
-			return m, cmd
+	}
+	m.selectedCommits = append(m.selectedCommits, realIdx)
+}
+
+// generateFromCommits writes a post about the selected commits, or the one
+// under the cursor when none are selected
+func (m Model) generateFromCommits() (tea.Model, tea.Cmd) {
+	if len(m.selectedCommits) == 0 && m.commitCursor < len(m.filteredCommits) {
+		m.selectedCommits = []int{m.filteredCommits[m.commitCursor]}
+	}
+	cmd := m.generateSuggestion("Generating suggestion...")
+	return m, cmd
+}
+
+func (m Model) handleGeneratingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		if m.cancelGeneration != nil {
+			m.cancelGeneration()
+			m.cancelGeneration = nil
 		}
-	default:
-		if m.commitSearchActive {
-			if len(msg.String()) == 1 {
-				m.commitSearch += msg.String()
-				m.filterCommits()
-				m.commitCursor = 0
-				m.commitScrollOffset = 0
-			}
-			return m, nil
+		m.generation++ // drop the cancelled run's result
+		m.generationPreview = ""
+		m.status = ""
+		m.err = nil
+		m.state = m.generationReturn
+		if m.state == stateSmartCompose {
+			m.textarea.Focus()
+			return m, textarea.Blink
 		}
-		if m.commitPromptActive {
-			var cmd tea.Cmd
-			m.commitPromptInput, cmd = m.commitPromptInput.Update(msg)
-			return m, cmd
+		return m, nil
+	case key.Matches(msg, m.keys.Quit):
+		if m.cancelGeneration != nil {
+			m.cancelGeneration()
 		}
+		return m, tea.Quit
 	}
 	return m, nil
 }
 
 // handleComposeKeys handles keys for both stateCompose and stateSmartCompose
 func (m Model) handleComposeKeys(msg tea.KeyMsg, isSmartPost bool) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Back):
 		if isSmartPost {
 			m.state = stateSmartMenu
 		} else {
 			m.state = stateHome
-			m.thread = []threadItem{{text: "", mediaIDs: nil, media: nil}}
+			m.thread = []threadItem{{text: "", media: nil}}
+			m.threadVersion++
 			m.currentPost = 0
+			m.reference = nil
 		}
 		m.textarea.Blur()
 		m.err = nil
 		return m, nil
 
-	case "ctrl+s":
+	case key.Matches(msg, k.Send):
 		m.thread[m.currentPost].text = m.textarea.Value()
+		if m.attaching > 0 {
+			m.err = fmt.Errorf("wait for the image to finish attaching")
+			return m, nil
+		}
 		if !m.hasContent() {
 			m.err = fmt.Errorf("post cannot be empty")
 			return m, nil
 		}
 		m.state = statePosting
-		m.status = "Posting..."
-		return m, m.doPost()
+		cmd := m.startPosting()
+		return m, cmd
 
-	case "ctrl+r":
-		if isSmartPost {
-			m.state = stateGenerating
-			m.status = "Regenerating..."
-			return m, m.generateSuggestion()
+	case key.Matches(msg, k.Regenerate) && isSmartPost:
+		m.textarea.Blur()
+		var cmd tea.Cmd
+		if m.askQuery != "" {
+			cmd = m.generateFromQuery("Regenerating...")
+		} else {
+			cmd = m.generateSuggestion("Regenerating...")
 		}
+		return m, cmd
 
-	case "ctrl+o":
-		if len(m.thread[m.currentPost].media) >= 4 {
-			m.err = fmt.Errorf("maximum 4 images per post")
+	case key.Matches(msg, k.AttachFile):
+		if err := m.attachError(); err != nil {
+			m.err = err
 			return m, nil
 		}
 		m.thread[m.currentPost].text = m.textarea.Value()
 		m.state = stateMediaInput
-		m.pathInput.SetValue("")
-		m.pathInput.Focus()
+		m.err = nil
+		recent := config.RecentDirs()
+		dir := "."
+		if len(recent) > 0 {
+			dir = recent[0]
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		m.picker.open(dir, maxMediaPerPost-len(m.thread[m.currentPost].media), recent)
 		return m, textinput.Blink
 
-	case "ctrl+n":
+	case key.Matches(msg, k.Split, k.SplitNumbered):
 		m.thread[m.currentPost].text = m.textarea.Value()
-		m.thread = append(m.thread, threadItem{text: "", mediaIDs: nil, media: nil})
-		m.currentPost = len(m.thread) - 1
-		m.textarea.SetValue("")
+		if !m.splitCurrentPost(key.Matches(msg, k.SplitNumbered)) {
+			m.err = fmt.Errorf("post already fits in %d characters", x.MaxPostLength)
+		} else {
+			m.err = nil
+		}
 		return m, nil
 
-	case "ctrl+d":
-		if len(m.thread) > 1 {
-			m.thread = append(m.thread[:m.currentPost], m.thread[m.currentPost+1:]...)
-			if m.currentPost >= len(m.thread) {
-				m.currentPost = len(m.thread) - 1
-			}
-			m.textarea.SetValue(m.thread[m.currentPost].text)
+	case key.Matches(msg, k.PasteImage):
+		if err := m.attachError(); err != nil {
+			m.err = err
+			return m, nil
 		}
-		return m, nil
+		m.attaching++
+		m.status = "Pasting image..."
+		m.err = nil
+		return m, m.pasteImage(m.currentPost, m.threadVersion)
 
-	case "ctrl+x":
-		if !isSmartPost && len(m.thread[m.currentPost].media) > 0 {
-			m.thread[m.currentPost].media = m.thread[m.currentPost].media[:len(m.thread[m.currentPost].media)-1]
-			m.thread[m.currentPost].mediaIDs = m.thread[m.currentPost].mediaIDs[:len(m.thread[m.currentPost].mediaIDs)-1]
+	case key.Matches(msg, k.CodeImage) && isSmartPost:
+		return m.openSnippetPicker()
+
+	case key.Matches(msg, k.Poll):
+		if len(m.thread[m.currentPost].media) > 0 {
+			m.err = fmt.Errorf("a post can't have both a poll and media")
+			return m, nil
 		}
+		m.syncCurrentPost()
+		m.textarea.Blur()
+		m.state = statePollEditor
+		m.err = nil
+		cmd := m.pollEditor.open(m.thread[m.currentPost].poll)
+		return m, cmd
+
+	case key.Matches(msg, k.Editor):
+		m.thread[m.currentPost].text = m.textarea.Value()
+		m.textarea.Blur()
+		return m, m.openEditor()
+
+	case key.Matches(msg, k.InsertAfter):
+		m.syncCurrentPost()
+		m.insertPost(1)
 		return m, nil
 
-	case "ctrl+left", "ctrl+b":
-		if isSmartPost && m.currentPost > 0 {
-			m.thread[m.currentPost].text = m.textarea.Value()
-			m.currentPost--
-			m.textarea.SetValue(m.thread[m.currentPost].text)
-		}
+	case key.Matches(msg, k.InsertBefore):
+		m.syncCurrentPost()
+		m.insertPost(0)
 		return m, nil
 
-	case "ctrl+right", "ctrl+f":
-		if isSmartPost && m.currentPost < len(m.thread)-1 {
-			m.thread[m.currentPost].text = m.textarea.Value()
-			m.currentPost++
-			m.textarea.SetValue(m.thread[m.currentPost].text)
+	case key.Matches(msg, k.DeletePost):
+		m.deletePost()
+		return m, nil
+
+	case key.Matches(msg, k.Duplicate):
+		m.syncCurrentPost()
+		m.duplicatePost()
+		return m, nil
+
+	case key.Matches(msg, k.Merge):
+		m.syncCurrentPost()
+		m.err = m.mergeWithNext()
+		return m, nil
+
+	case key.Matches(msg, k.MoveUp):
+		m.syncCurrentPost()
+		m.movePost(-1)
+		return m, nil
+
+	case key.Matches(msg, k.MoveDown):
+		m.syncCurrentPost()
+		m.movePost(1)
+		return m, nil
+
+	case key.Matches(msg, k.Outline):
+		m.syncCurrentPost()
+		m.textarea.Blur()
+		m.state = stateThreadOutline
+		return m, nil
+
+	case key.Matches(msg, k.RemoveMedia):
+		if len(m.thread[m.currentPost].media) > 0 {
+			m.thread[m.currentPost].media = m.thread[m.currentPost].media[:len(m.thread[m.currentPost].media)-1]
 		}
 		return m, nil
 
-	case "ctrl+up", "ctrl+k":
-		if !isSmartPost && m.currentPost > 0 {
-			m.thread[m.currentPost].text = m.textarea.Value()
+	case key.Matches(msg, k.PrevPost):
+		if m.currentPost > 0 {
+			m.syncCurrentPost()
 			m.currentPost--
-			m.textarea.SetValue(m.thread[m.currentPost].text)
+			m.loadCurrentPost()
 		}
 		return m, nil
 
-	case "ctrl+down", "ctrl+j":
-		if !isSmartPost && m.currentPost < len(m.thread)-1 {
-			m.thread[m.currentPost].text = m.textarea.Value()
+	case key.Matches(msg, k.NextPost):
+		if m.currentPost < len(m.thread)-1 {
+			m.syncCurrentPost()
 			m.currentPost++
-			m.textarea.SetValue(m.thread[m.currentPost].text)
+			m.loadCurrentPost()
 		}
 		return m, nil
 
-	case "ctrl+c":
+	case key.Matches(msg, k.Quit):
 		return m, tea.Quit
 	}
 
@@ -671,112 +971,315 @@ func (m Model) handleComposeKeys(msg tea.KeyMsg, isSmartPost bool) (tea.Model, t
 	return m, cmd
 }
 
-func (m Model) handleMediaInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+// handleThreadOutlineKeys edits the thread structure. The compose thread keys
+// work here too, besides the outline's single-letter ones.
+func (m Model) handleThreadOutlineKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, k.Back, k.Select, k.Outline):
 		if m.isSmartPost {
 			m.state = stateSmartCompose
 		} else {
 			m.state = stateCompose
 		}
-		m.pathInput.Blur()
 		m.textarea.Focus()
 		return m, textarea.Blink
-	case "enter":
-		path := strings.TrimSpace(m.pathInput.Value())
-		if path == "" {
-			if m.isSmartPost {
-				m.state = stateSmartCompose
-			} else {
-				m.state = stateCompose
-			}
-			m.textarea.Focus()
-			return m, textarea.Blink
+	case key.Matches(msg, k.MoveUp, k.OutlineMoveUp):
+		m.movePost(-1)
+	case key.Matches(msg, k.MoveDown, k.OutlineMoveDown):
+		m.movePost(1)
+	case key.Matches(msg, k.Up, k.PrevPost):
+		if m.currentPost > 0 {
+			m.currentPost--
+			m.loadCurrentPost()
 		}
-		// Clean up paths from drag-and-drop (escaped spaces, quotes)
-		path = strings.ReplaceAll(path, "\\ ", " ")
-		path = strings.Trim(path, "'\"")
-		if strings.HasPrefix(path, "~/") {
-			home, _ := os.UserHomeDir()
-			path = filepath.Join(home, path[2:])
-		}
-		ext := strings.ToLower(filepath.Ext(path))
-		validExts := map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true}
-		if !validExts[ext] {
-			m.err = fmt.Errorf("unsupported file type: %s", ext)
-			if m.isSmartPost {
-				m.state = stateSmartCompose
-			} else {
-				m.state = stateCompose
-			}
-			m.textarea.Focus()
-			return m, textarea.Blink
+	case key.Matches(msg, k.Down, k.NextPost):
+		if m.currentPost < len(m.thread)-1 {
+			m.currentPost++
+			m.loadCurrentPost()
 		}
-		m.status = "Uploading..."
-		return m, m.uploadMedia(path)
-	case "ctrl+c":
+	case key.Matches(msg, k.InsertAfter, k.OutlineAdd):
+		m.insertPost(1)
+	case key.Matches(msg, k.InsertBefore, k.OutlineAddBefore):
+		m.insertPost(0)
+	case key.Matches(msg, k.Duplicate, k.OutlineDuplicate):
+		m.duplicatePost()
+	case key.Matches(msg, k.Merge, k.OutlineMerge):
+		m.err = m.mergeWithNext()
+	case key.Matches(msg, k.DeletePost, k.OutlineDelete):
+		m.deletePost()
+	}
+	return m, nil
+}
+
+func (m Model) handleMediaInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.picker
+	k := m.keys
+	switch {
+	case key.Matches(msg, k.Quit):
 		return m, tea.Quit
+	case key.Matches(msg, k.Back):
+		if p.input.Value() != "" {
+			p.input.SetValue("")
+			p.filter()
+			return m, nil
+		}
+		m.state = m.composeState()
+		p.input.Blur()
+		m.textarea.Focus()
+		return m, textarea.Blink
+	case key.Matches(msg, k.FilterUp):
+		p.moveCursor(-1)
+		return m, nil
+	case key.Matches(msg, k.FilterDown):
+		p.moveCursor(1)
+		return m, nil
+	case key.Matches(msg, k.PageUp):
+		p.moveCursor(-maxVisibleFiles)
+		return m, nil
+	case key.Matches(msg, k.PageDown):
+		p.moveCursor(maxVisibleFiles)
+		return m, nil
+	case key.Matches(msg, k.ToggleFile):
+		p.toggleSelected()
+		return m, nil
+	case key.Matches(msg, k.ParentDir) && p.input.Value() == "":
+		p.chdir(filepath.Dir(p.dir))
+		return m, nil
+	case key.Matches(msg, k.RecentDir):
+		// The nth key of the binding opens the nth recent folder
+		if n := slices.Index(k.RecentDir.Keys(), msg.String()); n >= 0 && n < len(p.recent) {
+			p.chdir(p.recent[n])
+		}
+		return m, nil
+	case key.Matches(msg, k.Select):
+		input := p.input.Value()
+		if isTypedPath(input) {
+			paths, err := resolveTypedPath(input, p.dir)
+			if err != nil {
+				p.err = err
+				return m, nil
+			}
+			if len(paths) == 1 {
+				if info, err := os.Stat(paths[0]); err == nil && info.IsDir() {
+					p.chdir(paths[0])
+					return m, nil
+				}
+			}
+			return m.attachMedia(paths)
+		}
+		if len(p.selected) > 0 {
+			return m.attachMedia(p.selected)
+		}
+		entry, ok := p.current()
+		if !ok {
+			return m, nil
+		}
+		if entry.isDir {
+			p.chdir(filepath.Clean(filepath.Join(p.dir, entry.name)))
+			return m, nil
+		}
+		return m.attachMedia([]string{filepath.Join(p.dir, entry.name)})
 	}
+
+	before := p.input.Value()
 	var cmd tea.Cmd
-	m.pathInput, cmd = m.pathInput.Update(msg)
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.err = nil
+		p.filter()
+	}
 	return m, cmd
 }
 
+// attachMedia validates the chosen files and adds them to the current post.
+// They are uploaded when the thread is posted.
+func (m Model) attachMedia(paths []string) (tea.Model, tea.Cmd) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			m.picker.err = fmt.Errorf("cannot read %s", filepath.Base(path))
+			return m, nil
+		}
+		if info.IsDir() {
+			continue
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); !supportedMediaExts[ext] {
+			// Globs may match other files - only complain about explicit choices
+			if len(paths) == 1 {
+				m.picker.err = fmt.Errorf("unsupported file type: %s", ext)
+				return m, nil
+			}
+			continue
+		}
+		files = append(files, path)
+	}
+
+	if len(files) == 0 {
+		m.picker.err = fmt.Errorf("no supported images found")
+		return m, nil
+	}
+	if len(files) > m.picker.limit {
+		m.picker.err = fmt.Errorf("only %d more image(s) fit in this post (%d matched)", m.picker.limit, len(files))
+		return m, nil
+	}
+
+	config.AddRecentDir(filepath.Dir(files[0]))
+
+	m.picker.input.Blur()
+	m.state = m.composeState()
+	m.thread[m.currentPost].media = append(m.thread[m.currentPost].media, files...)
+	m.textarea.Focus()
+	return m, textarea.Blink
+}
+
 func (m Model) handlePostedKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q", "ctrl+c", "esc", "enter":
+	if m.deleting {
+		if key.Matches(msg, m.keys.Quit) {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+	k := m.keys
+	if m.confirmUndo {
+		switch {
+		case key.Matches(msg, k.Confirm):
+			m.confirmUndo = false
+			m.deleting = true
+			m.err = nil
+			return m, m.deletePosts(m.postIDs)
+		case key.Matches(msg, k.Quit):
+			return m, tea.Quit
+		default:
+			m.confirmUndo = false
+			return m, nil
+		}
+	}
+
+	switch {
+	case key.Matches(msg, k.Close, k.Quit, k.Back, k.Select):
 		return m, tea.Quit
-	case "n":
+	case key.Matches(msg, k.Undo):
+		if len(m.postIDs) > 0 {
+			m.confirmUndo = true
+		}
+		return m, nil
+	case key.Matches(msg, k.NewPost):
 		m.state = stateHome
 		m.status = ""
 		m.postURL = ""
 		m.postURLs = nil
+		m.postIDs = nil
 		m.err = nil
-		m.thread = []threadItem{{text: "", mediaIDs: nil, media: nil}}
+		m.thread = []threadItem{{text: "", media: nil}}
+		m.threadVersion++
 		m.currentPost = 0
 		m.isSmartPost = false
+		m.reference = nil
 		return m, nil
 	}
 	return m, nil
 }
 
+// handleDeleteResult returns to compose with the draft after an undo, so a
+// typo can be fixed and the post sent again
+func (m Model) handleDeleteResult(msg deleteResultMsg) (tea.Model, tea.Cmd) {
+	m.deleting = false
+	remaining := len(m.postIDs) - msg.deleted
+	m.postIDs = m.postIDs[:remaining]
+	m.postURLs = m.postURLs[:remaining]
+
+	// Deleted posts must no longer serve as style examples
+	entries, _ := history.Load()
+	m.pastPosts = history.Texts(entries)
+
+	if msg.err != nil {
+		m.err = errors.Join(msg.err, msg.historyErr)
+		return m, nil
+	}
+
+	m.postURL = ""
+	m.state = m.composeState()
+	m.currentPost = 0
+	m.loadCurrentPost()
+	m.textarea.Focus()
+	m.status = fmt.Sprintf("Deleted %d post(s) - your draft is back", msg.deleted)
+	m.err = msg.historyErr
+	return m, textarea.Blink
+}
+
 // Helper methods
 
-func (m *Model) filterCommits() {
-	if m.commitSearch == "" {
-		m.filteredCommits = make([]int, len(m.commits))
-		for i := range m.commits {
-			m.filteredCommits[i] = i
-		}
-		return
+// splitCurrentPost replaces the current post with a thread of posts that each
+// fit the length limit. Attached media and polls stay with the first post.
+// Returns false if the post didn't need splitting.
+func (m *Model) splitCurrentPost(numbered bool) bool {
+	item := m.thread[m.currentPost]
+	parts := x.SplitThread(item.text, x.SplitOptions{Numbered: numbered})
+	if len(parts) < 2 {
+		return false
 	}
 
-	search := strings.ToLower(m.commitSearch)
-	m.filteredCommits = nil
-	for i, commit := range m.commits {
-		if strings.Contains(strings.ToLower(commit.Subject), search) {
-			m.filteredCommits = append(m.filteredCommits, i)
-		}
+	items := make([]threadItem, len(parts))
+	for i, part := range parts {
+		items[i] = threadItem{text: part}
+	}
+	items[0].media = item.media
+	items[0].poll = item.poll
+
+	thread := append([]threadItem{}, m.thread[:m.currentPost]...)
+	thread = append(thread, items...)
+	m.thread = append(thread, m.thread[m.currentPost+1:]...)
+	m.threadVersion++
+	m.textarea.SetValue(m.thread[m.currentPost].text)
+	return true
+}
+
+// composeState returns the compose state for the current post type
+func (m Model) composeState() state {
+	if m.isSmartPost {
+		return stateSmartCompose
 	}
+	return stateCompose
+}
+
+// acceptsText reports whether the current screen has a focused text input
+func (m Model) acceptsText() bool {
+	switch m.state {
+	case stateCompose, stateSmartCompose, stateAskInput, stateMediaInput, stateCodeSnippet,
+		statePollEditor, stateReferenceInput, stateContinueThread:
+		return true
+	case stateCommitBrowser:
+		return m.commitSearch.Focused() || m.commitPromptActive
+	}
+	return false
 }
 
 func (m Model) hasContent() bool {
 	for _, item := range m.thread {
-		if strings.TrimSpace(item.text) != "" || len(item.mediaIDs) > 0 {
+		if strings.TrimSpace(item.text) != "" || len(item.media) > 0 {
 			return true
 		}
 	}
 	return strings.TrimSpace(m.textarea.Value()) != ""
 }
 
-// Run starts the TUI
-func Run() error {
-	m, err := New()
+// Run starts the TUI. repoPaths are the repositories given with --repo.
+func Run(repoPaths []string) error {
+	m, err := New(repoPaths)
 	if err != nil {
 		return err
 	}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err = p.Run()
+	final, err := p.Run()
+	if fm, ok := final.(Model); ok {
+		for _, path := range fm.tempFiles {
+			os.Remove(path)
+		}
+	}
 	return err
 }
@@ -0,0 +1,31 @@
+package tui
+
+// maxParallelUploads bounds how many files are uploaded at once
+const maxParallelUploads = 3
+
+type uploadState int
+
+const (
+	uploadQueued uploadState = iota
+	uploadRunning
+	uploadDone
+	uploadFailed
+)
+
+// upload tracks one attached file while the thread is being posted
+type upload struct {
+	path  string
+	state uploadState
+	err   error
+}
+
+// uploadsDone returns how many uploads have finished, successfully or not
+func (m Model) uploadsDone() int {
+	done := 0
+	for _, u := range m.uploads {
+		if u.state == uploadDone || u.state == uploadFailed {
+			done++
+		}
+	}
+	return done
+}
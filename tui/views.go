@@ -3,18 +3,19 @@ package tui
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
-	"unicode/utf8"
-)
 
-const (
-	minTerminalHeight = 30
-	minTerminalWidth  = 128
-	maxVisibleCommits = 5
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tomswokowski/shippost/x"
 )
 
 // View renders the current state of the TUI
 func (m Model) View() string {
+	return m.kittyDeletes() + m.view()
+}
+
+func (m Model) view() string {
 	// Check for minimum terminal size
 	if m.height > 0 && m.height < minTerminalHeight {
 		return m.viewTooSmall()
@@ -24,11 +25,12 @@ func (m Model) View() string {
 	}
 
 	var b strings.Builder
+	b.WriteString(m.renderHeader())
 
-	b.WriteString(titleStyle.Render("shippost"))
-	b.WriteString("  ")
-	b.WriteString(taglineStyle.Render("Share your work with the world"))
-	b.WriteString("\n\n")
+	if m.showHelp {
+		m.viewHelp(&b)
+		return b.String()
+	}
 
 	switch m.state {
 	case stateHome:
@@ -40,13 +42,29 @@ func (m Model) View() string {
 	case stateCommitBrowser:
 		m.viewCommitBrowser(&b)
 	case stateGenerating:
-		m.viewGenerating(&b)
+		if m.generationReturn == stateCommitBrowser && m.wide() {
+			m.viewCommitBrowser(&b)
+		} else {
+			m.viewGenerating(&b)
+		}
 	case stateSmartCompose:
 		m.viewCompose(&b, true)
 	case stateCompose, statePosting:
 		m.viewCompose(&b, false)
 	case stateMediaInput:
 		m.viewMediaInput(&b)
+	case stateThreadOutline:
+		m.viewThreadOutline(&b)
+	case stateCodeSnippet:
+		m.viewCodeSnippet(&b)
+	case statePollEditor:
+		m.viewPollEditor(&b)
+	case stateReferenceInput:
+		m.viewReferenceInput(&b)
+	case stateContinueThread:
+		m.viewContinueThread(&b)
+	case stateStats:
+		m.viewStats(&b)
 	case statePosted:
 		m.viewPosted(&b)
 	}
@@ -54,6 +72,15 @@ func (m Model) View() string {
 	return b.String()
 }
 
+// renderHeader is the title line shown above every view; compact terminals
+// drop the tagline and the blank line under it
+func (m Model) renderHeader() string {
+	if m.compact() {
+		return titleStyle.Render("shippost") + "\n"
+	}
+	return titleStyle.Render("shippost") + "  " + taglineStyle.Render("Share your work with the world") + "\n\n"
+}
+
 func (m Model) viewTooSmall() string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("shippost"))
@@ -68,46 +95,40 @@ func (m Model) viewTooSmall() string {
 
 func (m Model) viewHome(b *strings.Builder) {
 	for i, item := range m.menuItems {
-		if i == m.menuCursor {
-			b.WriteString(bulletStyle.Render("▸ "))
-			if item.enabled {
-				b.WriteString(selectedStyle.Render(item.title))
-			} else {
-				b.WriteString(disabledStyle.Render(item.title))
-			}
-			b.WriteString("\n")
-			if item.enabled {
-				b.WriteString(selectedDescStyle.Render(item.description))
-			} else {
-				b.WriteString(disabledDescStyle.Render(item.description))
-			}
-		} else {
-			b.WriteString(dimBulletStyle.Render("  "))
-			if item.enabled {
-				b.WriteString(menuItemStyle.Render(item.title))
-			} else {
-				b.WriteString(disabledStyle.Render(item.title))
-			}
-			b.WriteString("\n")
-			if item.enabled {
-				b.WriteString(menuDescStyle.Render(item.description))
-			} else {
-				b.WriteString(disabledDescStyle.Render(item.description))
-			}
-		}
-		b.WriteString("\n\n")
+		m.renderMenuItem(b, item.title, item.description, i == m.menuCursor, item.enabled)
+	}
+
+	for _, err := range m.skippedRepos {
+		b.WriteString(warningStyle.Render("⚠ " + err.Error()))
+		b.WriteString("\n")
 	}
 
+	if m.err != nil {
+		b.WriteString(errorStyle.Render("✗ " + m.err.Error()))
+		b.WriteString("\n")
+	}
+	if m.hookPrompt {
+		b.WriteString(warningStyle.Render(m.hookPromptText() + " "))
+		b.WriteString(m.renderHelpBar([]helpItem{
+			helpFor("draft", m.keys.Confirm),
+			helpFor("dismiss", m.keys.Decline),
+			{"any key", "later"},
+		}))
+		return
+	}
+
+	k := m.keys
 	b.WriteString(m.renderHelpBar([]helpItem{
-		{"↑↓", "navigate"},
-		{"enter", "select"},
-		{"q", "quit"},
+		helpFor("navigate", k.Up, k.Down),
+		helpFor("select", k.Select),
+		helpFor("all keys", k.Help),
+		helpFor("quit", k.Close),
 	}))
 }
 
 func (m Model) viewSmartMenu(b *strings.Builder) {
 	b.WriteString(subtitleStyle.Render("Smart Post"))
-	b.WriteString("\n\n")
+	b.WriteString(m.gap())
 
 	smartMenuItems := []struct {
 		title string
@@ -118,24 +139,14 @@ func (m Model) viewSmartMenu(b *strings.Builder) {
 	}
 
 	for i, item := range smartMenuItems {
-		if i == m.smartMenuCursor {
-			b.WriteString(bulletStyle.Render("▸ "))
-			b.WriteString(selectedStyle.Render(item.title))
-			b.WriteString("\n")
-			b.WriteString(selectedDescStyle.Render(item.desc))
-		} else {
-			b.WriteString(dimBulletStyle.Render("  "))
-			b.WriteString(menuItemStyle.Render(item.title))
-			b.WriteString("\n")
-			b.WriteString(menuDescStyle.Render(item.desc))
-		}
-		b.WriteString("\n\n")
+		m.renderMenuItem(b, item.title, item.desc, i == m.smartMenuCursor, true)
 	}
 
+	k := m.keys
 	b.WriteString(m.renderHelpBar([]helpItem{
-		{"↑↓", "navigate"},
-		{"enter", "select"},
-		{"esc", "back"},
+		helpFor("navigate", k.Up, k.Down),
+		helpFor("select", k.Select),
+		helpFor("back", k.Back),
 	}))
 }
 
@@ -143,21 +154,32 @@ func (m Model) viewAskInput(b *strings.Builder) {
 	b.WriteString(subtitleStyle.Render("Smart Post"))
 	b.WriteString("  ")
 	b.WriteString(aiTagStyle.Render(" Ask "))
-	b.WriteString("\n\n")
+	b.WriteString(m.gap())
 
 	b.WriteString(dimStyle.Render("What would you like to post about?"))
-	b.WriteString("\n\n")
+	b.WriteString(m.gap())
 
 	b.WriteString(activeBoxStyle.Render(m.askInput.View()))
-	b.WriteString("\n\n")
+	b.WriteString(m.gap())
 
 	if m.err != nil {
 		b.WriteString(errorStyle.Render("✗ " + m.err.Error()))
-		b.WriteString("\n\n")
+		b.WriteString(m.gap())
 	}
 
 	m.renderThreadToggle(b)
 
+	k := m.keys
+	items := []helpItem{
+		helpFor("generate", k.Generate),
+		helpFor("single/thread", k.ToggleThread),
+		helpFor("back", k.Back),
+	}
+	if m.compact() {
+		b.WriteString(m.renderHelpBar(items))
+		return
+	}
+
 	b.WriteString(dimStyle.Render("Examples:"))
 	b.WriteString("\n")
 	b.WriteString(dimStyle.Render("  • What did I accomplish today?"))
@@ -166,107 +188,171 @@ func (m Model) viewAskInput(b *strings.Builder) {
 	b.WriteString("\n")
 	b.WriteString(dimStyle.Render("  • Summarize my recent refactoring work"))
 	b.WriteString("\n\n")
-
-	b.WriteString(m.renderHelpBar([]helpItem{
-		{"ctrl+g", "generate"},
-		{"ctrl+t", "single/thread"},
-		{"esc", "back"},
-	}))
+	b.WriteString(m.renderHelpBar(items))
 }
 
+// viewCommitBrowser lists commits to post about. Wide terminals show a
+// pane beside the list with the highlighted commit's details, or the draft
+// as it streams in; smaller ones show the details in place of the list.
 func (m Model) viewCommitBrowser(b *strings.Builder) {
-	maxVisible := maxVisibleCommits
+	b.WriteString(m.renderCommitBrowserTop())
+
+	body := m.renderCommitList() + m.renderCommitBrowserBottom()
+	switch {
+	case m.wide():
+		left, right := m.panes()
+		column := lipgloss.NewStyle().Width(left).Render(strings.TrimSuffix(body, "\n"))
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, column, "  ", m.renderPreviewPane(right, m.commitBodyHeight())))
+		b.WriteString("\n")
+	case m.commitDetail.open && m.state == stateCommitBrowser:
+		width, height := m.detailSize()
+		b.WriteString(m.renderCommitDetail(width, height))
+		b.WriteString("\n")
+	default:
+		b.WriteString(body)
+	}
 
+	b.WriteString(m.renderHelpBar(m.commitBrowserHelpItems()))
+}
+
+// renderCommitBrowserTop is the heading and search bar above the commit list
+func (m Model) renderCommitBrowserTop() string {
+	var b strings.Builder
 	b.WriteString(subtitleStyle.Render("Smart Post"))
 	b.WriteString("  ")
 	b.WriteString(dimStyle.Render("Select commits to post about"))
-	b.WriteString("\n\n")
+	b.WriteString(m.gap())
 
-	// Search bar
-	if m.commitSearchActive {
-		b.WriteString(dimStyle.Render("/"))
-		b.WriteString(selectedStyle.Render(m.commitSearch))
-		b.WriteString(selectedStyle.Render("▌"))
-		b.WriteString("\n\n")
-	} else if m.commitSearch != "" {
-		b.WriteString(dimStyle.Render("/"))
-		b.WriteString(menuItemStyle.Render(m.commitSearch))
-		b.WriteString("  ")
-		b.WriteString(dimStyle.Render(fmt.Sprintf("(%d matches)", len(m.filteredCommits))))
-		b.WriteString("\n\n")
+	if m.commitSearch.Focused() || m.commitSearch.Value() != "" {
+		if m.commitSearch.Focused() {
+			b.WriteString(m.commitSearch.View())
+		} else {
+			b.WriteString(dimStyle.Render("/"))
+			b.WriteString(menuItemStyle.Render(m.commitSearch.Value()))
+		}
+		if m.commitSearch.Value() != "" {
+			b.WriteString("  ")
+			b.WriteString(dimStyle.Render(fmt.Sprintf("(%d matches)", len(m.filteredCommits))))
+		}
+		b.WriteString("\n")
+		if m.commitSearchErr != nil {
+			b.WriteString(warningStyle.Render("⚠ " + m.commitSearchErr.Error()))
+			b.WriteString("\n")
+		}
+		if !m.compact() {
+			b.WriteString("\n")
+		}
 	}
+	return b.String()
+}
 
-	if m.status != "" {
+// renderCommitList shows the window of commits that fits the terminal
+func (m Model) renderCommitList() string {
+	var b strings.Builder
+
+	switch {
+	case m.status != "" && m.state != stateGenerating:
 		b.WriteString(statusStyle.Render("● " + m.status))
 		b.WriteString("\n")
-	} else if m.err != nil {
+		return b.String()
+	case m.err != nil:
 		b.WriteString(errorStyle.Render("✗ " + m.err.Error()))
 		b.WriteString("\n")
-	} else if len(m.commits) == 0 {
+		return b.String()
+	case len(m.commits) == 0:
 		b.WriteString(dimStyle.Render("No commits found in this repository"))
-	} else if len(m.filteredCommits) == 0 {
+		b.WriteString("\n")
+		return b.String()
+	case len(m.filteredCommits) == 0:
 		b.WriteString(dimStyle.Render("No matching commits"))
-	} else {
-		// Show scroll indicator if there are more commits above
-		if m.commitScrollOffset > 0 {
-			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more above", m.commitScrollOffset)))
-			b.WriteString("\n")
-		}
+		b.WriteString("\n")
+		return b.String()
+	}
 
-		// Show visible window of commits
-		end := m.commitScrollOffset + maxVisible
-		if end > len(m.filteredCommits) {
-			end = len(m.filteredCommits)
-		}
+	width := m.contentWidth()
+	if m.wide() {
+		width, _ = m.panes()
+	}
+	// Room left for the subject after the cursor, checkbox, age and repo
+	// columns
+	repoWidth := m.repoLabelWidth()
+	if m.compact() {
+		repoWidth = min(repoWidth, 8)
+	}
+	subjectWidth := max(10, width-17)
+	if repoWidth > 0 {
+		subjectWidth = max(10, subjectWidth-repoWidth-1)
+	}
 
-		for i := m.commitScrollOffset; i < end; i++ {
-			realIdx := m.filteredCommits[i]
-			commit := m.commits[realIdx]
+	// The window shrinks when the selection count or prompt appear - keep
+	// the cursor in it
+	visible := m.visibleCommits()
+	offset := max(m.commitScrollOffset, m.commitCursor-visible+1)
 
-			isSelected := false
-			for _, s := range m.selectedCommits {
-				if s == realIdx {
-					isSelected = true
-					break
-				}
-			}
+	if offset > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more above", offset)))
+		b.WriteString("\n")
+	}
 
-			if i == m.commitCursor {
-				b.WriteString(bulletStyle.Render("▸ "))
-			} else {
-				b.WriteString("  ")
-			}
+	end := min(offset+visible, len(m.filteredCommits))
+	for i := offset; i < end; i++ {
+		realIdx := m.filteredCommits[i]
+		commit := m.commits[realIdx]
 
-			if isSelected {
-				b.WriteString(selectedStyle.Render("● "))
-			} else {
-				b.WriteString(dimStyle.Render("○ "))
-			}
+		if i == m.commitCursor {
+			b.WriteString(bulletStyle.Render("▸ "))
+		} else {
+			b.WriteString("  ")
+		}
 
-			b.WriteString(commitTimeStyle.Render(fmt.Sprintf("%-12s ", commit.Ago)))
+		if slices.Contains(m.selectedCommits, realIdx) {
+			b.WriteString(selectedStyle.Render("● "))
+		} else {
+			b.WriteString(dimStyle.Render("○ "))
+		}
 
-			if i == m.commitCursor {
-				b.WriteString(selectedStyle.Render(truncate(commit.Subject, 45)))
-			} else {
-				b.WriteString(menuItemStyle.Render(truncate(commit.Subject, 45)))
-			}
-			b.WriteString("\n")
+		b.WriteString(commitTimeStyle.Render(fmt.Sprintf("%-12s ", commit.Ago)))
+		if repoWidth > 0 {
+			b.WriteString(commitHashStyle.Render(fmt.Sprintf("%-*s ", repoWidth, truncate(commit.Repo.Name, repoWidth))))
 		}
 
-		// Show scroll indicator if there are more commits below
-		remaining := len(m.filteredCommits) - end
-		if remaining > 0 {
-			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more below", remaining)))
-			b.WriteString("\n")
+		base := menuItemStyle.Render
+		if i == m.commitCursor {
+			base = selectedStyle.Render
 		}
+		b.WriteString(renderCommitSubject(commit.Subject, subjectWidth, m.commitHighlights[realIdx], base))
+		b.WriteString("\n")
+	}
 
-		if len(m.selectedCommits) > 0 {
+	if remaining := len(m.filteredCommits) - end; remaining > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more below", remaining)))
+		b.WriteString("\n")
+	} else if m.loadingMoreCommits {
+		b.WriteString(dimStyle.Render("    ↓ loading older commits..."))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderCommitBrowserBottom is the selection count, prompt and thread toggle
+// under the commit list. Compact terminals only show the prompt once it is
+// in use.
+func (m Model) renderCommitBrowserBottom() string {
+	var b strings.Builder
+	compact := m.compact()
+
+	if len(m.selectedCommits) > 0 {
+		if !compact {
 			b.WriteString("\n")
-			b.WriteString(dimStyle.Render(fmt.Sprintf("%d commit(s) selected", len(m.selectedCommits))))
 		}
+		b.WriteString(dimStyle.Render(fmt.Sprintf("%d commit(s) selected", len(m.selectedCommits))))
+		b.WriteString("\n")
+	}
 
-		// Prompt input
-		b.WriteString("\n\n")
+	if !compact || m.commitPromptActive || m.commitPromptInput.Value() != "" {
+		if !compact {
+			b.WriteString("\n")
+		}
 		b.WriteString(inputLabelStyle.Render("Prompt "))
 		b.WriteString(dimStyle.Render("(optional)"))
 		b.WriteString("\n")
@@ -275,35 +361,101 @@ func (m Model) viewCommitBrowser(b *strings.Builder) {
 		} else {
 			b.WriteString(boxStyle.Render(m.commitPromptInput.View()))
 		}
+		b.WriteString("\n")
 	}
 
-	// Thread mode toggle (always visible)
-	b.WriteString("\n\n")
-	m.renderThreadToggle(b)
+	if !compact {
+		b.WriteString("\n")
+	}
+	m.renderThreadToggle(&b)
+	return b.String()
+}
 
-	b.WriteString("\n")
+// renderPreviewPane is the right-hand pane of the wide commit browser: the
+// draft as it streams in, or else the highlighted commit's details
+func (m Model) renderPreviewPane(width, height int) string {
+	inner := width - 4  // border and padding
+	lines := height - 2 // border
+
+	var b strings.Builder
+	if m.state == stateGenerating {
+		b.WriteString(aiTagStyle.Render(" Preview "))
+		b.WriteString("\n\n")
+		b.WriteString(statusStyle.Render("● " + m.status))
+		b.WriteString("\n\n")
+		if m.generationPreview == "" {
+			b.WriteString(dimStyle.Render("Claude is writing your post..."))
+		} else {
+			wrapped := lipgloss.NewStyle().Width(inner).Render(strings.TrimSpace(m.generationPreview))
+			b.WriteString(tail(wrapped, lines-4))
+		}
+	} else if m.status == "" && m.err == nil && len(m.filteredCommits) > 0 {
+		b.WriteString(m.renderCommitDetail(inner, lines))
+	}
+
+	return boxStyle.Width(width - 2).Height(lines).Render(b.String())
+}
+
+// commitBrowserHelpItems is the help bar of the commit browser, or of the
+// generation running in its preview pane
+func (m Model) commitBrowserHelpItems() []helpItem {
+	if m.state == stateGenerating {
+		return []helpItem{helpFor("cancel", m.keys.Back)}
+	}
+	if m.commitSearch.Focused() {
+		return []helpItem{
+			helpFor("results", m.keys.FilterUp, m.keys.FilterDown),
+			helpFor("done", m.keys.Select),
+			helpFor("clear", m.keys.Back),
+		}
+	}
 	searchHelp := "search"
-	if !m.commitSearchActive && m.commitSearch != "" {
+	if m.commitSearch.Value() != "" {
 		searchHelp = "clear"
 	}
-	b.WriteString(m.renderHelpBar([]helpItem{
-		{"↑↓", "navigate"},
-		{"space", "select"},
-		{"a", "all"},
-		{"/", searchHelp},
-		{"tab", "prompt"},
-		{"ctrl+t", "single/thread"},
-		{"ctrl+g", "generate"},
-		{"esc", "back"},
-	}))
+	k := m.keys
+	items := []helpItem{
+		helpFor("navigate", k.Up, k.Down),
+		helpFor("select", k.ToggleCommit),
+		helpFor("generate", k.Generate),
+	}
+	if !m.wide() {
+		items = append(items, helpFor("details", k.Details))
+	}
+	if m.detailShown() {
+		items = append(items, helpFor("scroll", k.ScrollUp, k.ScrollDown))
+	}
+	return append(items,
+		helpFor("all", k.SelectAll),
+		helpFor(searchHelp, k.Search),
+		helpFor("prompt", k.FocusPrompt),
+		helpFor("single/thread", k.ToggleThread),
+		helpFor("back", k.Back),
+	)
 }
 
 func (m Model) viewGenerating(b *strings.Builder) {
 	b.WriteString(subtitleStyle.Render("Smart Post"))
-	b.WriteString("\n\n")
+	b.WriteString(m.gap())
 	b.WriteString(statusStyle.Render("● " + m.status))
-	b.WriteString("\n\n")
-	b.WriteString(dimStyle.Render("Claude is writing your post..."))
+	b.WriteString(m.gap())
+
+	if m.generationPreview == "" {
+		b.WriteString(dimStyle.Render("Claude is writing your post..."))
+		b.WriteString("\n")
+	} else {
+		// Show the tail of the streamed response so the latest text stays
+		// visible, as much of it as the terminal has room for
+		_, height := m.size()
+		maxPreviewLines := max(3, min(12, height-lipgloss.Height(m.renderHeader())-10))
+		wrapped := lipgloss.NewStyle().Width(m.boxWidth(70)).Render(strings.TrimSpace(m.generationPreview))
+		b.WriteString(boxStyle.Render(tail(wrapped, maxPreviewLines)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.renderHelpBar([]helpItem{
+		helpFor("cancel", m.keys.Back),
+	}))
 }
 
 // viewCompose renders both Quick Post and Smart Post compose screens
@@ -312,6 +464,12 @@ func (m Model) viewCompose(b *strings.Builder, isSmartPost bool) {
 		b.WriteString(subtitleStyle.Render("Smart Post"))
 		b.WriteString("  ")
 		b.WriteString(aiTagStyle.Render(" AI "))
+	} else if m.reference != nil && m.reference.quote {
+		b.WriteString(subtitleStyle.Render("Quote Post"))
+	} else if m.reference != nil && m.reference.continuing {
+		b.WriteString(subtitleStyle.Render("Continue Thread"))
+	} else if m.reference != nil {
+		b.WriteString(subtitleStyle.Render("Reply"))
 	} else {
 		b.WriteString(subtitleStyle.Render("Quick Post"))
 	}
@@ -337,7 +495,14 @@ func (m Model) viewCompose(b *strings.Builder, isSmartPost bool) {
 		}
 		b.WriteString("\n")
 	}
-	b.WriteString("\n")
+	if !m.compact() {
+		b.WriteString("\n")
+	}
+
+	if m.reference != nil {
+		m.renderReference(b)
+		b.WriteString("\n")
+	}
 
 	// Textarea
 	if m.state == statePosting {
@@ -350,30 +515,67 @@ func (m Model) viewCompose(b *strings.Builder, isSmartPost bool) {
 	// Character count
 	m.renderCharCount(b)
 
-	// Media tags
-	if len(m.thread[m.currentPost].media) > 0 {
+	// Media tags with thumbnails
+	if media := m.thread[m.currentPost].media; len(media) > 0 {
 		b.WriteString("\n")
-		for i, path := range m.thread[m.currentPost].media {
+		var previews []*mediaPreview
+		var captions []string
+		for i, path := range media {
+			preview := m.previewFor(path)
 			b.WriteString("  ")
 			b.WriteString(mediaTagStyle.Render(fmt.Sprintf(" 📎 %d. %s ", i+1, filepath.Base(path))))
+			b.WriteString(dimStyle.Render("  " + preview.info()))
+			b.WriteString("\n")
+			if preview.err == nil {
+				previews = append(previews, preview)
+				captions = append(captions, fmt.Sprintf("%d. %s", i+1, filepath.Base(path)))
+			}
+		}
+		if thumbs := m.renderThumbnails(previews, captions); thumbs != "" && !m.compact() {
+			b.WriteString("\n")
+			b.WriteString(thumbs)
 			b.WriteString("\n")
 		}
 	}
 
+	// Poll choices
+	if poll := m.thread[m.currentPost].poll; poll != nil {
+		b.WriteString("\n")
+		b.WriteString(mediaTagStyle.Render(fmt.Sprintf(" 📊 Poll · %s ", x.FormatPollDuration(poll.DurationMinutes))))
+		b.WriteString("\n")
+		for _, option := range poll.Options {
+			b.WriteString(dimStyle.Render("  ○ " + option))
+			b.WriteString("\n")
+		}
+	}
+
+	// Near-duplicate warning for AI suggestions
+	if dup := m.thread[m.currentPost].duplicateOf; dup != "" {
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render("⚠ Very similar to a post you already published:"))
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("  " + truncate(strings.ReplaceAll(dup, "\n", " "), min(70, m.contentWidth()-2))))
+		b.WriteString("\n")
+	}
+
 	// Error
 	if m.err != nil {
 		b.WriteString("\n")
 		b.WriteString(errorStyle.Render("✗ " + m.err.Error()))
 	}
 
-	// Status for posting state
-	if m.state == statePosting {
+	// Status for posting state and attachments in flight
+	if m.state == statePosting || m.attaching > 0 {
 		b.WriteString("\n")
 		b.WriteString(statusStyle.Render("● " + m.status))
 	}
+	if m.state == statePosting {
+		m.renderUploads(b)
+	}
 
-	// Thread preview for Quick Post with multiple posts
-	if !isSmartPost && len(m.thread) > 1 {
+	// Thread preview for Quick Post with multiple posts; compact terminals
+	// rely on the indicator dots and the outline instead
+	if !isSmartPost && len(m.thread) > 1 && !m.compact() {
 		b.WriteString("\n\n")
 		b.WriteString(dimStyle.Render("Thread:"))
 		b.WriteString("\n")
@@ -411,27 +613,331 @@ func (m Model) viewCompose(b *strings.Builder, isSmartPost bool) {
 	b.WriteString(m.renderHelpBar(m.composeHelpItems(isSmartPost)))
 }
 
+// viewThreadOutline shows every post in the thread with its character count
+func (m Model) viewThreadOutline(b *strings.Builder) {
+	b.WriteString(subtitleStyle.Render("Thread Outline"))
+	b.WriteString("  ")
+	b.WriteString(threadNumStyle.Render(fmt.Sprintf(" %d posts ", len(m.thread))))
+	b.WriteString("\n\n")
+
+	width := m.boxWidth(70)
+	var blocks []string
+	for i, item := range m.thread {
+		var block strings.Builder
+
+		if i == m.currentPost {
+			block.WriteString(bulletStyle.Render("▸ "))
+			block.WriteString(selectedStyle.Render(fmt.Sprintf("%d.", i+1)))
+		} else {
+			block.WriteString(dimBulletStyle.Render("  "))
+			block.WriteString(menuItemStyle.Render(fmt.Sprintf("%d.", i+1)))
+		}
+
+		count := x.WeightedLength(item.text)
+		countStyle := helpTextStyle
+		if count > x.MaxPostLength {
+			countStyle = errorStyle
+		}
+		block.WriteString(" ")
+		block.WriteString(countStyle.Render(fmt.Sprintf("%d/%d", count, x.MaxPostLength)))
+		if len(item.media) > 0 {
+			block.WriteString(dimStyle.Render(fmt.Sprintf("  📎 %d", len(item.media))))
+		}
+		if item.poll != nil {
+			block.WriteString(dimStyle.Render(fmt.Sprintf("  📊 %d choices", len(item.poll.Options))))
+		}
+		block.WriteString("\n")
+
+		text := strings.TrimSpace(item.text)
+		if text == "" {
+			text = "(empty)"
+		}
+		textStyle := dimStyle
+		if i == m.currentPost {
+			textStyle = menuItemStyle
+		}
+		block.WriteString(textStyle.Width(width).PaddingLeft(4).Render(text))
+		blocks = append(blocks, block.String())
+	}
+
+	// Keep the current post on screen when the thread is taller than the terminal
+	start := 0
+	if m.height > 0 {
+		available := m.height - lipgloss.Height(m.renderHeader()) - 8
+		used := 0
+		for i := m.currentPost; i >= 0; i-- {
+			used += strings.Count(blocks[i], "\n") + 2
+			if used > available && i < m.currentPost {
+				start = i + 1
+				break
+			}
+		}
+	}
+	if start > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more above", start)))
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Join(blocks[start:], "\n\n"))
+	b.WriteString("\n")
+
+	if m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("✗ " + m.err.Error()))
+		b.WriteString("\n")
+	}
+
+	k := m.keys
+	b.WriteString(m.renderHelpBar([]helpItem{
+		helpFor("navigate", k.Up, k.Down),
+		helpFor("move", k.OutlineMoveDown, k.OutlineMoveUp),
+		helpFor("insert after/before", k.OutlineAdd, k.OutlineAddBefore),
+		helpFor("duplicate", k.OutlineDuplicate),
+		helpFor("merge next", k.OutlineMerge),
+		helpFor("delete", k.OutlineDelete),
+		helpFor("edit", k.Select),
+	}))
+}
+
 func (m Model) viewMediaInput(b *strings.Builder) {
+	p := m.picker
+
 	b.WriteString(subtitleStyle.Render("Attach Image"))
+	b.WriteString("  ")
+	b.WriteString(dimStyle.Render(abbreviateHome(p.dir)))
 	b.WriteString("\n\n")
-	b.WriteString(inputLabelStyle.Render("File path:"))
+
+	// Recent directory shortcuts, shortened to share the line
+	if len(p.recent) > 0 {
+		recentWidth := min(24, max(8, (m.contentWidth()-8)/len(p.recent)-8))
+		b.WriteString(dimStyle.Render("Recent: "))
+		for i, dir := range p.recent {
+			if i > 0 {
+				b.WriteString(dimStyle.Render("  "))
+			}
+			if keys := m.keys.RecentDir.Keys(); m.keys.RecentDir.Enabled() && i < len(keys) {
+				b.WriteString(helpTextStyle.Render(keyName(keys[i]) + " "))
+			}
+			b.WriteString(menuItemStyle.Render(truncate(abbreviateHome(dir), recentWidth)))
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(activeBoxStyle.Render(p.input.View()))
 	b.WriteString("\n")
-	b.WriteString(activeBoxStyle.Render(m.pathInput.View()))
+
+	if isTypedPath(p.input.Value()) {
+		b.WriteString(dimStyle.Render("  " + m.keys.Select.Help().Key + " to attach this path (globs like *.png allowed)"))
+		b.WriteString("\n")
+	} else if len(p.matches) == 0 {
+		b.WriteString(dimStyle.Render("  No folders or images here"))
+		b.WriteString("\n")
+	} else {
+		if p.offset > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more above", p.offset)))
+			b.WriteString("\n")
+		}
+		end := min(p.offset+maxVisibleFiles, len(p.matches))
+		for i := p.offset; i < end; i++ {
+			match := p.matches[i]
+			entry := p.entries[match.index]
+
+			if i == p.cursor {
+				b.WriteString(bulletStyle.Render("▸ "))
+			} else {
+				b.WriteString("  ")
+			}
+
+			name := entry.name
+			if entry.isDir {
+				name += "/"
+				b.WriteString(dimStyle.Render("  "))
+			} else if p.isSelected(filepath.Join(p.dir, entry.name)) {
+				b.WriteString(selectedStyle.Render("● "))
+			} else {
+				b.WriteString(dimStyle.Render("○ "))
+			}
+
+			base := menuItemStyle.Render
+			if i == p.cursor {
+				base = selectedStyle.Render
+			} else if entry.isDir {
+				base = inputLabelStyle.Render
+			}
+			b.WriteString(highlightMatches(name, match.positions, base, selectedStyle.Underline(true).Render))
+			b.WriteString("\n")
+		}
+		if remaining := len(p.matches) - end; remaining > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more below", remaining)))
+			b.WriteString("\n")
+		}
+	}
+
+	// Preview the highlighted image
+	if entry, ok := p.current(); ok && !entry.isDir && !isTypedPath(p.input.Value()) {
+		preview := m.previewFor(filepath.Join(p.dir, entry.name))
+		b.WriteString("\n")
+		if thumbs := m.renderThumbnails([]*mediaPreview{preview}, []string{preview.info()}); thumbs != "" && preview.err == nil {
+			b.WriteString(thumbs)
+		} else {
+			b.WriteString(dimStyle.Render(preview.info()))
+		}
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
-	b.WriteString(dimStyle.Render("Supports: .jpg .png .gif .webp • Use ~/path for home"))
+	b.WriteString(dimStyle.Render(fmt.Sprintf("Supports: .jpg .png .gif .webp • %d of %d selected", len(p.selected), p.limit)))
 
-	if m.status != "" {
+	if p.err != nil {
 		b.WriteString("\n")
-		b.WriteString(statusStyle.Render("● " + m.status))
+		b.WriteString(errorStyle.Render("✗ " + p.err.Error()))
 	}
 
 	b.WriteString("\n")
+	k := m.keys
+	b.WriteString(m.renderHelpBar([]helpItem{
+		helpFor("navigate", k.FilterUp, k.FilterDown),
+		helpFor("open/attach", k.Select),
+		helpFor("select", k.ToggleFile),
+		helpFor("parent", k.ParentDir),
+		helpFor("recent", k.RecentDir),
+		helpFor("cancel", k.Back),
+	}))
+}
+
+func (m Model) viewCodeSnippet(b *strings.Builder) {
+	p := m.snippets
+
+	b.WriteString(subtitleStyle.Render("Code Image"))
+	b.WriteString("  ")
+	b.WriteString(dimStyle.Render("pick a change to render with syntax highlighting"))
+	b.WriteString("\n\n")
+
+	switch {
+	case p.loading:
+		b.WriteString(statusStyle.Render("● Loading diff..."))
+		b.WriteString("\n")
+	case len(p.hunks) == 0:
+		b.WriteString(dimStyle.Render("  No changes found in the selected commits"))
+		b.WriteString("\n")
+	default:
+		if p.offset > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↑ %d more above", p.offset)))
+			b.WriteString("\n")
+		}
+		end := min(p.offset+maxVisibleHunks, len(p.hunks))
+		for i := p.offset; i < end; i++ {
+			hunk := p.hunks[i]
+			if i == p.cursor {
+				b.WriteString(bulletStyle.Render("▸ "))
+				b.WriteString(selectedStyle.Render(hunk.File))
+			} else {
+				b.WriteString("  ")
+				b.WriteString(menuItemStyle.Render(hunk.File))
+			}
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  +%d  %s", hunk.NewStart, truncate(hunk.Context(), max(10, min(40, m.contentWidth()-len(hunk.File)-12))))))
+			b.WriteString("\n")
+		}
+		if remaining := len(p.hunks) - end; remaining > 0 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ↓ %d more below", remaining)))
+			b.WriteString("\n")
+		}
+
+		// Preview the start of the highlighted hunk
+		if p.cursor < len(p.hunks) {
+			b.WriteString("\n")
+			lines := p.hunks[p.cursor].Lines
+			shown := 8
+			if m.compact() {
+				shown = 4
+			}
+			for _, line := range lines[:min(len(lines), shown)] {
+				style := dimStyle
+				switch line[0] {
+				case '+':
+					style = statusStyle
+				case '-':
+					style = errorStyle
+				}
+				b.WriteString("  ")
+				b.WriteString(style.Render(truncate(strings.ReplaceAll(line, "\t", "    "), min(76, m.contentWidth()-2))))
+				b.WriteString("\n")
+			}
+			if len(lines) > shown {
+				b.WriteString(dimStyle.Render(fmt.Sprintf("  … %d more lines", len(lines)-shown)))
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(activeBoxStyle.Render(p.input.View()))
+	b.WriteString("\n")
+
+	if p.err != nil {
+		b.WriteString(errorStyle.Render("✗ " + p.err.Error()))
+		b.WriteString("\n")
+	}
+
+	k := m.keys
 	b.WriteString(m.renderHelpBar([]helpItem{
-		{"enter", "upload"},
-		{"esc", "cancel"},
+		helpFor("navigate", k.FilterUp, k.FilterDown),
+		helpFor("render & attach", k.Select),
+		helpFor("cancel", k.Back),
 	}))
 }
 
+func (m Model) viewPollEditor(b *strings.Builder) {
+	e := m.pollEditor
+
+	b.WriteString(subtitleStyle.Render("Poll"))
+	b.WriteString("  ")
+	b.WriteString(dimStyle.Render(fmt.Sprintf("%d-%d choices, up to %d characters each", x.MinPollOptions, x.MaxPollOptions, x.MaxPollOptionLength)))
+	b.WriteString("\n\n")
+
+	for i, in := range e.inputs {
+		style := boxStyle
+		if i == e.focus {
+			style = activeBoxStyle
+		}
+		b.WriteString(style.Render(in.View()))
+		b.WriteString(" ")
+		b.WriteString(helpTextStyle.Render(fmt.Sprintf("%d/%d", len([]rune(in.Value())), x.MaxPollOptionLength)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	label := inputLabelStyle.Render("Duration  ")
+	duration := x.FormatPollDuration(pollDurations[e.duration])
+	if e.focus == len(e.inputs) {
+		b.WriteString(bulletStyle.Render("▸ "))
+		b.WriteString(label)
+		b.WriteString(selectedStyle.Render("◂ " + duration + " ▸"))
+	} else {
+		b.WriteString("  ")
+		b.WriteString(label)
+		b.WriteString(menuItemStyle.Render(duration))
+	}
+	b.WriteString("\n")
+
+	if e.err != nil {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("✗ " + e.err.Error()))
+		b.WriteString("\n")
+	}
+
+	k := m.keys
+	items := []helpItem{
+		helpFor("next/previous field", k.NextField, k.PrevField),
+		helpFor("duration", k.ShorterPoll, k.LongerPoll),
+		helpFor("save", k.Select),
+	}
+	if m.thread[m.currentPost].poll != nil {
+		items = append(items, helpFor("remove poll", k.RemovePoll))
+	}
+	items = append(items, helpFor("cancel", k.Back))
+	b.WriteString(m.renderHelpBar(items))
+}
+
 func (m Model) viewPosted(b *strings.Builder) {
 	if len(m.postURLs) > 1 {
 		b.WriteString(statusStyle.Render(fmt.Sprintf("✓ Thread posted! (%d posts)", len(m.postURLs))))
@@ -449,12 +955,144 @@ func (m Model) viewPosted(b *strings.Builder) {
 	}
 
 	b.WriteString("\n")
+	switch {
+	case m.deleting:
+		b.WriteString(statusStyle.Render("● Deleting..."))
+		b.WriteString("\n")
+		return
+	case m.confirmUndo:
+		b.WriteString(warningStyle.Render(fmt.Sprintf("Delete %d post(s) from X? ", len(m.postIDs))))
+		b.WriteString(m.renderHelpBar([]helpItem{
+			helpFor("delete", m.keys.Confirm),
+			{"any key", "cancel"},
+		}))
+		return
+	}
+	if m.err != nil {
+		b.WriteString(errorStyle.Render("✗ " + m.err.Error()))
+		b.WriteString("\n")
+	}
 	b.WriteString(m.renderHelpBar([]helpItem{
-		{"n", "new post"},
-		{"q", "quit"},
+		helpFor("undo (delete)", m.keys.Undo),
+		helpFor("new post", m.keys.NewPost),
+		helpFor("quit", m.keys.Close),
 	}))
 }
 
+// viewHelp lists every action in the keymap with its keys. When they don't
+// all fit, only the general keys and those of the current screen are shown.
+func (m Model) viewHelp(b *strings.Builder) {
+	b.WriteString(subtitleStyle.Render("Keys"))
+	if !m.compact() {
+		b.WriteString("  ")
+		b.WriteString(dimStyle.Render("rebind any of these in ~/.config/shippost/keys.json"))
+	}
+	b.WriteString(m.gap())
+
+	sections := m.keys.sections()
+	columns := make([]string, len(sections))
+	for i, section := range sections {
+		var col strings.Builder
+		col.WriteString(inputLabelStyle.Render(section.title))
+		col.WriteString("\n")
+		for _, a := range section.actions {
+			keys := a.binding.Help().Key
+			if !a.binding.Enabled() {
+				keys = "(none)"
+			}
+			col.WriteString(helpKeyStyle.Width(16).Render(keys))
+			col.WriteString(" ")
+			col.WriteString(helpTextStyle.Width(22).Render(a.binding.Help().Desc))
+			if !m.compact() {
+				col.WriteString(dimStyle.Render(a.name))
+			}
+			col.WriteString("\n")
+		}
+		columns[i] = col.String()
+	}
+
+	keys := joinColumns(columns, m.contentWidth())
+
+	helpBar := m.renderHelpBar([]helpItem{{"any key", "close"}})
+	_, height := m.size()
+	available := height - lipgloss.Height(m.renderHeader()) - lipgloss.Height(helpBar) - 2
+	if lipgloss.Height(keys) > available {
+		current := slices.IndexFunc(sections, func(s keySection) bool { return s.title == m.helpSection() })
+		keys = columns[0] + "\n" + columns[current]
+		if hidden := lipgloss.Height(keys) - available; hidden > 0 {
+			keys = head(keys, available-1) + "\n" + dimStyle.Render(fmt.Sprintf("… %d more on a taller terminal", hidden+1))
+		}
+	}
+	b.WriteString(keys)
+	b.WriteString("\n")
+	b.WriteString(helpBar)
+}
+
+// joinColumns lays sections out side by side in as many columns as fit the
+// width, keeping their order and making the tallest column as short as it can
+func joinColumns(sections []string, width int) string {
+	const gap = "    "
+	sectionWidth, tallest := 0, 0
+	for _, section := range sections {
+		sectionWidth = max(sectionWidth, lipgloss.Width(section))
+		tallest = max(tallest, lipgloss.Height(section)+1)
+	}
+	n := max(1, min(len(sections), (width+len(gap))/(sectionWidth+len(gap))))
+
+	var columns []string
+	for limit := tallest; ; limit++ {
+		columns = packColumns(sections, limit)
+		if len(columns) <= n {
+			break
+		}
+	}
+
+	parts := make([]string, 0, 2*len(columns))
+	for i, c := range columns {
+		if i > 0 {
+			parts = append(parts, gap)
+		}
+		parts = append(parts, lipgloss.NewStyle().Width(sectionWidth).Render(c))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+// packColumns fills columns in order, starting a new one when the next
+// section would take the current one past limit lines
+func packColumns(sections []string, limit int) []string {
+	var columns, col []string
+	height := 0
+	for _, section := range sections {
+		h := lipgloss.Height(section) + 1
+		if len(col) > 0 && height+h > limit {
+			columns = append(columns, strings.Join(col, "\n"))
+			col, height = nil, 0
+		}
+		col = append(col, section)
+		height += h
+	}
+	return append(columns, strings.Join(col, "\n"))
+}
+
+// helpSection is the title of the keymap section for the current screen
+func (m Model) helpSection() string {
+	switch m.state {
+	case stateCommitBrowser:
+		return sectionCommits
+	case stateCompose, stateSmartCompose:
+		return sectionCompose
+	case stateThreadOutline:
+		return sectionOutline
+	case stateMediaInput, stateCodeSnippet, stateContinueThread, stateReferenceInput:
+		return sectionPickers
+	case statePollEditor:
+		return sectionPoll
+	case stateStats, statePosted:
+		return sectionPosts
+	}
+	return sectionLists
+}
+
 // Helper methods for views
 
 type helpItem struct {
@@ -462,15 +1100,92 @@ type helpItem struct {
 	text string
 }
 
+// renderHelpBar lays out help items across the terminal, wrapping onto more
+// lines when they don't fit. Compact terminals get at most two lines, ending
+// with the key for the full list when items had to be left out.
 func (m Model) renderHelpBar(items []helpItem) string {
+	const compactHelpLines = 2
+
 	var parts []string
 	for _, item := range items {
-		parts = append(parts, helpKeyStyle.Render(item.key)+" "+helpTextStyle.Render(item.text))
+		if item.key == "" {
+			continue // action disabled in the keymap
+		}
+		parts = append(parts, renderHelpItem(item))
+	}
+	width := m.contentWidth()
+	bar := wrapHelp(parts, width)
+
+	style := helpBarStyle
+	if m.compact() {
+		style = style.UnsetPaddingTop().BorderBottom(false)
+		var more []string
+		if item := typingHelp(m.keys.Help); item.key != "" {
+			more = append(more, renderHelpItem(item))
+		}
+		for len(parts) > 1 && lipgloss.Height(bar) > compactHelpLines {
+			parts = parts[:len(parts)-1]
+			bar = wrapHelp(append(slices.Clip(parts), more...), width)
+		}
 	}
-	return helpBarStyle.Render(strings.Join(parts, "   "))
+	return style.Render(bar)
+}
+
+func renderHelpItem(item helpItem) string {
+	return helpKeyStyle.Render(item.key) + " " + helpTextStyle.Render(item.text)
+}
+
+// renderMenuItem renders a menu entry with its description. Compact
+// terminals only describe the highlighted entry.
+func (m Model) renderMenuItem(b *strings.Builder, title, desc string, selected, enabled bool) {
+	itemStyle, descStyle := menuItemStyle, menuDescStyle
+	if selected {
+		itemStyle, descStyle = selectedStyle, selectedDescStyle
+	}
+	if !enabled {
+		itemStyle, descStyle = disabledStyle, disabledDescStyle
+	}
+
+	if selected {
+		b.WriteString(bulletStyle.Render("▸ "))
+	} else {
+		b.WriteString(dimBulletStyle.Render("  "))
+	}
+	b.WriteString(itemStyle.Render(title))
+	b.WriteString("\n")
+	if m.compact() {
+		if selected {
+			b.WriteString(descStyle.Render(desc))
+			b.WriteString("\n")
+		}
+		return
+	}
+	b.WriteString(descStyle.Render(desc))
+	b.WriteString("\n\n")
+}
+
+// gap ends a heading line: followed by a blank line, except on compact
+// terminals where every line counts
+func (m Model) gap() string {
+	if m.compact() {
+		return "\n"
+	}
+	return "\n\n"
 }
 
 func (m Model) renderThreadToggle(b *strings.Builder) {
+	if m.compact() {
+		// Both choices on one line
+		if m.allowThread {
+			b.WriteString(selectedStyle.Render("● ") + menuItemStyle.Render("Allow threads"))
+			b.WriteString(dimStyle.Render("  ○ Single post only"))
+		} else {
+			b.WriteString(dimStyle.Render("○ Allow threads  "))
+			b.WriteString(selectedStyle.Render("● ") + menuItemStyle.Render("Single post only"))
+		}
+		b.WriteString("\n")
+		return
+	}
 	if m.allowThread {
 		b.WriteString(selectedStyle.Render("● "))
 		b.WriteString(menuItemStyle.Render("Allow threads"))
@@ -490,16 +1205,19 @@ func (m Model) renderThreadToggle(b *strings.Builder) {
 }
 
 func (m Model) renderCharCount(b *strings.Builder) {
-	charCount := utf8.RuneCountInString(m.textarea.Value())
+	charCount := x.WeightedLength(m.textarea.Value())
 	countStyle := helpTextStyle
-	if charCount > 260 {
+	if charCount > x.MaxPostLength-20 {
 		countStyle = warningStyle
 	}
-	if charCount > 280 {
+	if charCount > x.MaxPostLength {
 		countStyle = errorStyle
 	}
 	b.WriteString(countStyle.Render(fmt.Sprintf("%d", charCount)))
-	b.WriteString(helpTextStyle.Render("/280"))
+	b.WriteString(helpTextStyle.Render(fmt.Sprintf("/%d", x.MaxPostLength)))
+	if split := m.keys.Split; charCount > x.MaxPostLength && split.Enabled() {
+		b.WriteString(helpTextStyle.Render("  " + split.Help().Key + " to split into a thread"))
+	}
 }
 
 func (m Model) threadLabel(isSmartPost bool) string {
@@ -510,27 +1228,31 @@ func (m Model) threadLabel(isSmartPost bool) string {
 }
 
 func (m Model) composeHelpItems(isSmartPost bool) []helpItem {
-	items := []helpItem{
-		{"ctrl+s", "send"},
+	k := m.keys
+	items := []helpItem{helpFor("send", k.Send)}
+	if isSmartPost {
+		items = append(items, helpFor("regen", k.Regenerate))
 	}
+	items = append(items, helpFor("attach", k.AttachFile))
+	items = append(items, helpFor("paste image", k.PasteImage))
 	if isSmartPost {
-		items = append(items, helpItem{"ctrl+r", "regen"})
+		items = append(items, helpFor("code image", k.CodeImage))
 	}
-	items = append(items, helpItem{"ctrl+o", "attach"})
-	items = append(items, helpItem{"ctrl+n", "add"})
+	items = append(items, helpFor("poll", k.Poll))
+	items = append(items, helpFor("add", k.InsertAfter))
+	items = append(items, helpFor("editor", k.Editor))
 
-	if len(m.thread[m.currentPost].media) > 0 && !isSmartPost {
-		items = append(items, helpItem{"ctrl+x", "remove media"})
+	if len(m.thread[m.currentPost].media) > 0 {
+		items = append(items, helpFor("remove media", k.RemoveMedia))
 	}
+	items = append(items, helpFor("outline", k.Outline))
 	if len(m.thread) > 1 {
-		items = append(items, helpItem{"ctrl+d", "delete"})
-		if isSmartPost {
-			items = append(items, helpItem{"ctrl+b/f", "nav"})
-		} else {
-			items = append(items, helpItem{"ctrl+j/k", "nav"})
-		}
+		items = append(items, helpFor("delete", k.DeletePost))
+		items = append(items, helpFor("move", k.MoveUp, k.MoveDown))
+		items = append(items, helpFor("nav", k.PrevPost, k.NextPost))
 	}
-	items = append(items, helpItem{"esc", "back"})
+	items = append(items, typingHelp(k.Help))
+	items = append(items, helpFor("back", k.Back))
 	return items
 }
 
@@ -540,3 +1262,21 @@ func truncate(s string, max int) string {
 	}
 	return s[:max-3] + "..."
 }
+
+// renderUploads lists each file being uploaded with its progress
+func (m Model) renderUploads(b *strings.Builder) {
+	for _, u := range m.uploads {
+		b.WriteString("\n  ")
+		name := filepath.Base(u.path)
+		switch u.state {
+		case uploadQueued:
+			b.WriteString(dimStyle.Render("○ " + name + "  waiting"))
+		case uploadRunning:
+			b.WriteString(warningStyle.Render("↑ " + name + "  uploading..."))
+		case uploadDone:
+			b.WriteString(statusStyle.Render("✓ " + name))
+		case uploadFailed:
+			b.WriteString(errorStyle.Render("✗ " + name + "  " + u.err.Error()))
+		}
+	}
+}
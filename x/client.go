@@ -9,13 +9,12 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
-	"unicode/utf8"
 
 	"github.com/dghubble/oauth1"
 	"github.com/tomswokowski/shippost/config"
+	"github.com/tomswokowski/shippost/media"
 )
 
 const (
@@ -26,12 +25,15 @@ const (
 const (
 	postsEndpoint  = "https://api.x.com/2/tweets"
 	uploadEndpoint = "https://upload.twitter.com/1.1/media/upload.json"
-	maxPostLength  = 280
 )
 
+// MaxPostLength is the weighted character limit of a single post
+const MaxPostLength = 280
+
 // Client handles X API interactions
 type Client struct {
 	httpClient *http.Client
+	media      media.Options
 }
 
 // PostResponse represents the API response for creating a post
@@ -57,8 +59,39 @@ type APIError struct {
 
 // PostOptions contains optional parameters for posting
 type PostOptions struct {
-	ReplyToID string   // ID of post to reply to (for threads)
-	MediaIDs  []string // Media IDs to attach
+	ReplyToID   string   // ID of post to reply to (for threads)
+	QuotePostID string   // ID of post to quote
+	MediaIDs    []string // Media IDs to attach
+	Poll        *Poll    // Poll to attach (can't be combined with media or a quote)
+}
+
+// Post is an existing post fetched from X
+type Post struct {
+	ID             string
+	Text           string
+	AuthorName     string
+	AuthorUsername string
+}
+
+// API is the part of the X API shippost uses. Client talks to X; Fake keeps
+// everything in memory for trying shippost out without posting.
+type API interface {
+	PostWithOptions(text string, opts *PostOptions) (*PostResponse, error)
+	PostThread(posts []ThreadPost) ([]*PostResponse, error)
+	UploadMedia(filePath string) (*MediaResponse, error)
+	GetPost(id string) (*Post, error)
+	DeletePost(id string) error
+	GetPostMetrics(ids []string) (map[string]Metrics, error)
+}
+
+// New returns the fake API when SHIPPOST_FAKE is set, and a real client otherwise
+func New(cfg *config.Config) API {
+	if FakeEnabled() {
+		f := NewFake()
+		f.media = mediaOptions(cfg.Media)
+		return f
+	}
+	return NewClient(cfg)
 }
 
 // NewClient creates a new X API client with OAuth 1.0a authentication
@@ -72,7 +105,32 @@ func NewClient(cfg *config.Config) *Client {
 
 	return &Client{
 		httpClient: httpClient,
+		media:      mediaOptions(cfg.Media),
+	}
+}
+
+// mediaOptions applies the user's media preferences over the defaults
+func mediaOptions(mc config.MediaConfig) media.Options {
+	opts := media.DefaultOptions()
+	if mc.Resize != nil {
+		opts.Resize = *mc.Resize
+	}
+	if mc.MaxDimension > 0 {
+		opts.MaxDimension = mc.MaxDimension
 	}
+	if mc.Compress != nil {
+		opts.Compress = *mc.Compress
+	}
+	if mc.MaxBytes > 0 {
+		opts.MaxBytes = mc.MaxBytes
+	}
+	if mc.StripMetadata != nil {
+		opts.StripMetadata = *mc.StripMetadata
+	}
+	if mc.JPEGQuality > 0 {
+		opts.JPEGQuality = mc.JPEGQuality
+	}
+	return opts
 }
 
 // Post creates a new post on X
@@ -82,13 +140,8 @@ func (c *Client) Post(text string) (*PostResponse, error) {
 
 // PostWithOptions creates a new post with additional options
 func (c *Client) PostWithOptions(text string, opts *PostOptions) (*PostResponse, error) {
-	// Validate post length using rune count for proper Unicode support
-	runeCount := utf8.RuneCountInString(text)
-	if runeCount == 0 {
-		return nil, fmt.Errorf("post text cannot be empty")
-	}
-	if runeCount > maxPostLength {
-		return nil, fmt.Errorf("post exceeds %d characters (%d)", maxPostLength, runeCount)
+	if err := validatePost(text, opts); err != nil {
+		return nil, err
 	}
 
 	// Build request body
@@ -102,11 +155,20 @@ func (c *Client) PostWithOptions(text string, opts *PostOptions) (*PostResponse,
 				"in_reply_to_tweet_id": opts.ReplyToID,
 			}
 		}
+		if opts.QuotePostID != "" {
+			body["quote_tweet_id"] = opts.QuotePostID
+		}
 		if len(opts.MediaIDs) > 0 {
 			body["media"] = map[string]interface{}{
 				"media_ids": opts.MediaIDs,
 			}
 		}
+		if opts.Poll != nil {
+			body["poll"] = map[string]interface{}{
+				"options":          opts.Poll.Options,
+				"duration_minutes": opts.Poll.DurationMinutes,
+			}
+		}
 	}
 
 	jsonBody, err := json.Marshal(body)
@@ -148,39 +210,160 @@ func (c *Client) PostWithOptions(text string, opts *PostOptions) (*PostResponse,
 	return &postResp, nil
 }
 
+// validatePost checks text and options against X's rules before sending
+func validatePost(text string, opts *PostOptions) error {
+	// Validate post length the way X counts it (links and wide characters are weighted)
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("post text cannot be empty")
+	}
+	if length := WeightedLength(text); length > MaxPostLength {
+		return fmt.Errorf("post exceeds %d characters (%d)", MaxPostLength, length)
+	}
+
+	if opts != nil && opts.Poll != nil {
+		if len(opts.MediaIDs) > 0 {
+			return fmt.Errorf("a post can't have both a poll and media")
+		}
+		if opts.QuotePostID != "" {
+			return fmt.Errorf("a quote post can't have a poll")
+		}
+		if err := opts.Poll.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPost fetches a post and its author
+func (c *Client) GetPost(id string) (*Post, error) {
+	url := fmt.Sprintf("%s/%s?expansions=author_id&user.fields=username,name", postsEndpoint, id)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseAPIError(resp.StatusCode, respBody)
+	}
+
+	var postResp struct {
+		Data struct {
+			ID       string `json:"id"`
+			Text     string `json:"text"`
+			AuthorID string `json:"author_id"`
+		} `json:"data"`
+		Includes struct {
+			Users []struct {
+				ID       string `json:"id"`
+				Name     string `json:"name"`
+				Username string `json:"username"`
+			} `json:"users"`
+		} `json:"includes"`
+		Errors []APIError `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &postResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// Deleted or protected posts come back as 200 with only an errors array
+	if postResp.Data.ID == "" {
+		if len(postResp.Errors) > 0 {
+			return nil, fmt.Errorf("API error: %s", postResp.Errors[0].Detail)
+		}
+		return nil, fmt.Errorf("post %s not found", id)
+	}
+
+	post := &Post{ID: postResp.Data.ID, Text: postResp.Data.Text}
+	for _, user := range postResp.Includes.Users {
+		if user.ID == postResp.Data.AuthorID {
+			post.AuthorName = user.Name
+			post.AuthorUsername = user.Username
+		}
+	}
+	return post, nil
+}
+
+// DeletePost deletes one of your posts
+func (c *Client) DeletePost(id string) error {
+	req, err := http.NewRequest("DELETE", postsEndpoint+"/"+id, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return parseAPIError(resp.StatusCode, respBody)
+	}
+
+	var deleteResp struct {
+		Data struct {
+			Deleted bool `json:"deleted"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &deleteResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !deleteResp.Data.Deleted {
+		return fmt.Errorf("post %s was not deleted", id)
+	}
+	return nil
+}
+
 // UploadMedia uploads an image or video and returns the media ID
 func (c *Client) UploadMedia(filePath string) (*MediaResponse, error) {
+	result, err := processUpload(filePath, c.media)
+	if err != nil {
+		return nil, err
+	}
+	return c.uploadSimple(result.Data, result.MIMEType)
+}
+
+// processUpload reads a file to upload and resizes, recompresses and strips
+// its metadata with opts. Client and Fake both use it, so the fake rejects
+// and changes the same files a real upload would.
+func processUpload(filePath string, opts media.Options) (*media.Result, error) {
 	// Read file
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Determine media type
-	ext := strings.ToLower(filepath.Ext(filePath))
-	var mediaType string
-	switch ext {
-	case ".jpg", ".jpeg":
-		mediaType = "image/jpeg"
-	case ".png":
-		mediaType = "image/png"
-	case ".gif":
-		mediaType = "image/gif"
-	case ".webp":
-		mediaType = "image/webp"
-	case ".mp4":
-		mediaType = "video/mp4"
-	default:
-		return nil, fmt.Errorf("unsupported media type: %s", ext)
+	// Determine media type from the contents, not the extension
+	mediaType := media.DetectType(data)
+	if strings.HasPrefix(mediaType, "video/") {
+		// Videos would need chunked upload (not implemented yet)
+		return nil, fmt.Errorf("video upload not yet supported")
 	}
-
-	// For images, use simple upload
-	if strings.HasPrefix(mediaType, "image/") {
-		return c.uploadSimple(data, mediaType)
+	if !media.IsImage(mediaType) {
+		return nil, fmt.Errorf("unsupported media type: %s", mediaType)
 	}
 
-	// For videos, would need chunked upload (not implemented yet)
-	return nil, fmt.Errorf("video upload not yet supported")
+	result, err := media.Process(data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process image: %w", err)
+	}
+	return result, nil
 }
 
 // uploadSimple performs a simple media upload for images
@@ -240,12 +423,14 @@ func (c *Client) PostThread(posts []ThreadPost) ([]*PostResponse, error) {
 	}
 
 	var responses []*PostResponse
-	var replyToID string
+	replyToID := posts[0].ReplyToID
 
 	for i, post := range posts {
 		opts := &PostOptions{
-			ReplyToID: replyToID,
-			MediaIDs:  post.MediaIDs,
+			ReplyToID:   replyToID,
+			QuotePostID: post.QuotePostID,
+			MediaIDs:    post.MediaIDs,
+			Poll:        post.Poll,
 		}
 
 		resp, err := c.PostWithOptions(post.Text, opts)
@@ -260,10 +445,25 @@ func (c *Client) PostThread(posts []ThreadPost) ([]*PostResponse, error) {
 	return responses, nil
 }
 
+// DeleteThread deletes posts last to first, so replies never outlive the
+// posts they reply to. Returns the number deleted before any error, always
+// the last n of ids.
+func DeleteThread(api API, ids []string) (int, error) {
+	for i := len(ids) - 1; i >= 0; i-- {
+		if err := api.DeletePost(ids[i]); err != nil {
+			return len(ids) - 1 - i, fmt.Errorf("failed to delete post %s: %w", ids[i], err)
+		}
+	}
+	return len(ids), nil
+}
+
 // ThreadPost represents a single post in a thread
 type ThreadPost struct {
-	Text     string
-	MediaIDs []string
+	Text        string
+	MediaIDs    []string
+	Poll        *Poll
+	ReplyToID   string // only used on the first post; later posts reply to the previous one
+	QuotePostID string
 }
 
 // parseAPIError extracts error details from API response
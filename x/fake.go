@@ -0,0 +1,155 @@
+package x
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/tomswokowski/shippost/media"
+)
+
+// fakeFirstID is where fake post and media IDs start, so they look like real ones
+const fakeFirstID = 1900000000000000000
+
+// FakeEnabled reports whether SHIPPOST_FAKE asks for the in-memory fake API
+func FakeEnabled() bool {
+	v := os.Getenv("SHIPPOST_FAKE")
+	return v != "" && v != "0" && v != "false"
+}
+
+// Fake is an in-memory stand-in for the X API. It applies the same
+// validation as Client but never touches the network.
+type Fake struct {
+	mu     sync.Mutex
+	nextID int64
+	posts  map[string]*Post
+	sent   map[string]PostOptions // what each stored post replied to, quoted and attached
+	media  media.Options          // how uploads are processed, as in Client
+}
+
+// IsFake reports whether api is the in-memory fake. Fake posts never reached
+// X and their IDs may be real ones, so they are kept out of the post history
+// and the metrics cache.
+func IsFake(api API) bool {
+	_, fake := api.(*Fake)
+	return fake
+}
+
+// NewFake creates an empty fake API
+func NewFake() *Fake {
+	return &Fake{
+		nextID: fakeFirstID,
+		posts:  make(map[string]*Post),
+		sent:   make(map[string]PostOptions),
+		media:  media.DefaultOptions(),
+	}
+}
+
+func (f *Fake) newID() string {
+	f.nextID++
+	return strconv.FormatInt(f.nextID, 10)
+}
+
+// PostWithOptions validates and stores a post
+func (f *Fake) PostWithOptions(text string, opts *PostOptions) (*PostResponse, error) {
+	if err := validatePost(text, opts); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.newID()
+	f.posts[id] = &Post{ID: id, Text: text, AuthorName: "You", AuthorUsername: "you"}
+	if opts != nil {
+		f.sent[id] = *opts
+	}
+
+	var resp PostResponse
+	resp.Data.ID = id
+	resp.Data.Text = text
+	return &resp, nil
+}
+
+// PostThread stores each post in turn, like Client.PostThread
+func (f *Fake) PostThread(posts []ThreadPost) ([]*PostResponse, error) {
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("thread cannot be empty")
+	}
+
+	var responses []*PostResponse
+	replyToID := posts[0].ReplyToID
+	for i, post := range posts {
+		resp, err := f.PostWithOptions(post.Text, &PostOptions{
+			ReplyToID:   replyToID,
+			QuotePostID: post.QuotePostID,
+			MediaIDs:    post.MediaIDs,
+			Poll:        post.Poll,
+		})
+		if err != nil {
+			return responses, fmt.Errorf("failed to post thread item %d: %w", i+1, err)
+		}
+		responses = append(responses, resp)
+		replyToID = resp.Data.ID
+	}
+	return responses, nil
+}
+
+// UploadMedia processes the file exactly like a real upload, then discards it
+func (f *Fake) UploadMedia(filePath string) (*MediaResponse, error) {
+	if _, err := processUpload(filePath, f.media); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.newID()
+	n, _ := strconv.ParseInt(id, 10, 64)
+	return &MediaResponse{MediaID: n, MediaIDString: id}, nil
+}
+
+// GetPost returns a stored post, or a placeholder for any other ID
+func (f *Fake) GetPost(id string) (*Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if post, ok := f.posts[id]; ok {
+		return post, nil
+	}
+	return &Post{
+		ID:             id,
+		Text:           "Just shipped a new release! Release notes in the thread below.",
+		AuthorName:     "Example Dev",
+		AuthorUsername: "exampledev",
+	}, nil
+}
+
+// DeletePost forgets a stored post. Any other ID is assumed to be one of
+// yours and deletes successfully.
+func (f *Fake) DeletePost(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.posts, id)
+	delete(f.sent, id)
+	return nil
+}
+
+// GetPostMetrics makes up stable metrics for each ID, so the same post always
+// shows the same numbers
+func (f *Fake) GetPostMetrics(ids []string) (map[string]Metrics, error) {
+	metrics := make(map[string]Metrics, len(ids))
+	for _, id := range ids {
+		h := fnv.New32a()
+		h.Write([]byte(id))
+		n := int(h.Sum32() % 5000)
+		metrics[id] = Metrics{
+			Impressions: 200 + n,
+			Likes:       n / 40,
+			Reposts:     n / 250,
+			Replies:     n / 400,
+			Quotes:      n / 1000,
+			Bookmarks:   n / 300,
+		}
+	}
+	return metrics, nil
+}
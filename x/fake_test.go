@@ -0,0 +1,104 @@
+package x
+
+import (
+	"testing"
+
+	"github.com/tomswokowski/shippost/config"
+)
+
+// sentTo returns the reply and quote IDs a fake post was sent with
+func sentTo(t *testing.T, f *Fake, id string) (replyTo, quote string) {
+	t.Helper()
+	opts, ok := f.sent[id]
+	if !ok {
+		t.Fatalf("post %s was not stored", id)
+	}
+	return opts.ReplyToID, opts.QuotePostID
+}
+
+func TestFakeReply(t *testing.T) {
+	f := NewFake()
+	const parent = "1234567890"
+
+	resp, err := f.PostWithOptions("Congrats on the launch!", &PostOptions{ReplyToID: parent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replyTo, quote := sentTo(t, f, resp.Data.ID)
+	if replyTo != parent || quote != "" {
+		t.Errorf("sent reply_to=%q quote=%q, want reply_to=%q and no quote", replyTo, quote, parent)
+	}
+}
+
+func TestFakeQuoteThread(t *testing.T) {
+	f := NewFake()
+	const quoted = "1234567890"
+
+	responses, err := f.PostThread([]ThreadPost{
+		{Text: "This release is worth a look", QuotePostID: quoted},
+		{Text: "Here's what changed for us"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("got %d response(s), want 2", len(responses))
+	}
+
+	replyTo, quote := sentTo(t, f, responses[0].Data.ID)
+	if replyTo != "" || quote != quoted {
+		t.Errorf("first post sent reply_to=%q quote=%q, want no reply and quote=%q", replyTo, quote, quoted)
+	}
+	replyTo, quote = sentTo(t, f, responses[1].Data.ID)
+	if replyTo != responses[0].Data.ID || quote != "" {
+		t.Errorf("second post sent reply_to=%q quote=%q, want a reply to %s", replyTo, quote, responses[0].Data.ID)
+	}
+}
+
+func TestFakeContinueThread(t *testing.T) {
+	f := NewFake()
+
+	published, err := f.PostThread([]ThreadPost{{Text: "Shipping week, day 1"}, {Text: "Day 2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tail := published[len(published)-1].Data.ID
+
+	// Continuing a thread replies to its last post, then chains as usual
+	added, err := f.PostThread([]ThreadPost{{Text: "Day 3", ReplyToID: tail}, {Text: "Day 4"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replyTo, _ := sentTo(t, f, added[0].Data.ID); replyTo != tail {
+		t.Errorf("first new post replied to %q, want the thread's last post %s", replyTo, tail)
+	}
+	if replyTo, _ := sentTo(t, f, added[1].Data.ID); replyTo != added[0].Data.ID {
+		t.Errorf("second new post replied to %q, want %s", replyTo, added[0].Data.ID)
+	}
+}
+
+func TestFakeRejectsInvalidPosts(t *testing.T) {
+	f := NewFake()
+	poll := &Poll{Options: []string{"Yes", "No"}, DurationMinutes: 60}
+
+	if _, err := f.PostWithOptions("Which is better?", &PostOptions{QuotePostID: "1234567890", Poll: poll}); err == nil {
+		t.Error("a quote with a poll was accepted")
+	}
+	if len(f.sent) != 0 {
+		t.Errorf("rejected post was stored")
+	}
+}
+
+func TestFakeUsesConfiguredMediaOptions(t *testing.T) {
+	t.Setenv("SHIPPOST_FAKE", "1")
+	off := false
+	cfg := &config.Config{Media: config.MediaConfig{Resize: &off, MaxDimension: 1024}}
+
+	f, ok := New(cfg).(*Fake)
+	if !ok {
+		t.Fatal("New didn't return the fake with SHIPPOST_FAKE set")
+	}
+	if want := mediaOptions(cfg.Media); f.media != want {
+		t.Errorf("fake processes uploads with %+v, want %+v", f.media, want)
+	}
+}
@@ -0,0 +1,105 @@
+package x
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxMetricsBatch is the most post IDs the API accepts in one lookup
+const maxMetricsBatch = 100
+
+// Metrics is the engagement of a single post
+type Metrics struct {
+	Impressions int  `json:"impressions"`
+	Likes       int  `json:"likes"`
+	Reposts     int  `json:"reposts"`
+	Replies     int  `json:"replies"`
+	Quotes      int  `json:"quotes"`
+	Bookmarks   int  `json:"bookmarks"`
+	Private     bool `json:"private,omitempty"` // impressions came from non_public_metrics
+}
+
+// GetPostMetrics looks up the metrics of posts by ID. Posts that no longer
+// exist are left out of the result. Non-public metrics are only available
+// for your own posts from the last 30 days, so a batch falls back to public
+// metrics when the API refuses them.
+func (c *Client) GetPostMetrics(ids []string) (map[string]Metrics, error) {
+	metrics := make(map[string]Metrics, len(ids))
+	for start := 0; start < len(ids); start += maxMetricsBatch {
+		batch := ids[start:min(start+maxMetricsBatch, len(ids))]
+		err := c.lookupMetrics(batch, "public_metrics,non_public_metrics", metrics)
+		if err != nil {
+			err = c.lookupMetrics(batch, "public_metrics", metrics)
+		}
+		if err != nil {
+			return metrics, err
+		}
+	}
+	return metrics, nil
+}
+
+// lookupMetrics fetches fields for a batch of posts and adds them to metrics
+func (c *Client) lookupMetrics(ids []string, fields string, metrics map[string]Metrics) error {
+	query := url.Values{"ids": {strings.Join(ids, ",")}, "tweet.fields": {fields}}
+	req, err := http.NewRequest("GET", postsEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return parseAPIError(resp.StatusCode, respBody)
+	}
+
+	var lookupResp struct {
+		Data []struct {
+			ID            string `json:"id"`
+			PublicMetrics struct {
+				Impressions int `json:"impression_count"`
+				Likes       int `json:"like_count"`
+				Reposts     int `json:"retweet_count"`
+				Replies     int `json:"reply_count"`
+				Quotes      int `json:"quote_count"`
+				Bookmarks   int `json:"bookmark_count"`
+			} `json:"public_metrics"`
+			NonPublicMetrics *struct {
+				Impressions int `json:"impression_count"`
+			} `json:"non_public_metrics"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &lookupResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for _, post := range lookupResp.Data {
+		pm := post.PublicMetrics
+		m := Metrics{
+			Impressions: pm.Impressions,
+			Likes:       pm.Likes,
+			Reposts:     pm.Reposts,
+			Replies:     pm.Replies,
+			Quotes:      pm.Quotes,
+			Bookmarks:   pm.Bookmarks,
+		}
+		if npm := post.NonPublicMetrics; npm != nil {
+			m.Impressions = npm.Impressions
+			m.Private = true
+		}
+		metrics[post.ID] = m
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package x
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Poll limits enforced by X
+const (
+	MinPollOptions      = 2
+	MaxPollOptions      = 4
+	MaxPollOptionLength = 25
+	MinPollMinutes      = 5
+	MaxPollMinutes      = 7 * 24 * 60
+	DefaultPollMinutes  = 24 * 60
+)
+
+// Poll is a set of choices attached to a post
+type Poll struct {
+	Options         []string
+	DurationMinutes int
+}
+
+// Validate checks the poll against X's limits
+func (p *Poll) Validate() error {
+	if len(p.Options) < MinPollOptions || len(p.Options) > MaxPollOptions {
+		return fmt.Errorf("a poll needs %d to %d choices", MinPollOptions, MaxPollOptions)
+	}
+	seen := make(map[string]bool)
+	for i, option := range p.Options {
+		if strings.TrimSpace(option) == "" {
+			return fmt.Errorf("poll choice %d is empty", i+1)
+		}
+		if n := utf8.RuneCountInString(option); n > MaxPollOptionLength {
+			return fmt.Errorf("poll choice %d exceeds %d characters (%d)", i+1, MaxPollOptionLength, n)
+		}
+		if seen[option] {
+			return fmt.Errorf("poll choice %d is a duplicate", i+1)
+		}
+		seen[option] = true
+	}
+	if p.DurationMinutes < MinPollMinutes || p.DurationMinutes > MaxPollMinutes {
+		return fmt.Errorf("poll duration must be between %d minutes and 7 days", MinPollMinutes)
+	}
+	return nil
+}
+
+// FormatPollDuration renders a duration in minutes like "1 day" or "6 hours"
+func FormatPollDuration(minutes int) string {
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", name)
+		}
+		return fmt.Sprintf("%d %ss", n, name)
+	}
+	switch {
+	case minutes%(24*60) == 0:
+		return unit(minutes/(24*60), "day")
+	case minutes%60 == 0:
+		return unit(minutes/60, "hour")
+	}
+	return unit(minutes, "minute")
+}
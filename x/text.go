@@ -0,0 +1,228 @@
+package x
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// urlLength is the weight X gives every link, regardless of its length
+const urlLength = 23
+
+// urlPattern matches links the way X counts them: up to the next whitespace
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// paragraphBreak matches blank lines between paragraphs
+var paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+
+// sentenceEnd matches sentence-ending punctuation followed by whitespace
+var sentenceEnd = regexp.MustCompile(`[.!?…]+["')\]]*\s+`)
+
+// SplitOptions controls how SplitThread breaks up long text
+type SplitOptions struct {
+	Numbered  bool // Append a "1/n" counter to every post
+	MaxLength int  // Weighted length limit per post (defaults to MaxPostLength)
+}
+
+// PostURL returns the public URL of a post
+func PostURL(id string) string {
+	return fmt.Sprintf("https://x.com/i/status/%s", id)
+}
+
+// postIDPattern matches the numeric ID in a post URL, e.g. x.com/user/status/123
+var postIDPattern = regexp.MustCompile(`(?:twitter\.com|x\.com)/(?:[A-Za-z0-9_]+|i(?:/web)?)/status(?:es)?/(\d+)`)
+
+// ParsePostID extracts the post ID from a post URL, or accepts a bare numeric ID
+func ParsePostID(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", fmt.Errorf("no post URL or ID given")
+	}
+	if strings.Trim(input, "0123456789") == "" {
+		return input, nil
+	}
+	if m := postIDPattern.FindStringSubmatch(input); m != nil {
+		return m[1], nil
+	}
+	return "", fmt.Errorf("not a post URL or ID: %s", input)
+}
+
+// WeightedLength returns the length of text as counted by X: links count as
+// 23 characters and wide characters (CJK, emoji) count double
+func WeightedLength(text string) int {
+	length := 0
+	prev := 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		length += runeWeight(text[prev:loc[0]]) + urlLength
+		prev = loc[1]
+	}
+	return length + runeWeight(text[prev:])
+}
+
+// runeWeight sums the X weight of each character in s
+func runeWeight(s string) int {
+	weight := 0
+	for _, r := range s {
+		switch {
+		case r <= 0x10FF, r >= 0x2000 && r <= 0x200D, r >= 0x2010 && r <= 0x201F, r >= 0x2032 && r <= 0x2037:
+			weight++
+		default:
+			weight += 2
+		}
+	}
+	return weight
+}
+
+// SplitThread breaks text into posts that each fit the weighted length limit.
+// It prefers paragraph, line and sentence boundaries, falls back to word boundaries,
+// and never splits a word or link. A single word longer than the limit is
+// kept whole in its own post.
+func SplitThread(text string, opts SplitOptions) []string {
+	limit := opts.MaxLength
+	if limit <= 0 {
+		limit = MaxPostLength
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	if !opts.Numbered {
+		return splitToLimit(text, limit)
+	}
+
+	// Reserve room for the counter; more posts may need a wider counter
+	var posts []string
+	for n := 1; ; {
+		reserve := len(counter(n, n))
+		posts = splitToLimit(text, limit-reserve)
+		if len(counter(len(posts), len(posts))) <= reserve {
+			break
+		}
+		n = len(posts)
+	}
+
+	if len(posts) == 1 {
+		return posts
+	}
+	for i := range posts {
+		posts[i] += counter(i+1, len(posts))
+	}
+	return posts
+}
+
+// counter formats the " i/n" suffix added to numbered posts
+func counter(i, n int) string {
+	return fmt.Sprintf(" %d/%d", i, n)
+}
+
+// splitToLimit greedily packs paragraphs, then lines, then sentences, then
+// words into posts. Line breaks are kept, so lists stay lists.
+func splitToLimit(text string, limit int) []string {
+	var posts []string
+	var current string
+
+	flush := func() {
+		if strings.TrimSpace(current) != "" {
+			posts = append(posts, strings.TrimSpace(current))
+		}
+		current = ""
+	}
+
+	// add appends a piece to the current post, starting a new post if it doesn't fit
+	add := func(piece, sep string) bool {
+		if current == "" {
+			if WeightedLength(piece) <= limit {
+				current = piece
+				return true
+			}
+			return false
+		}
+		if WeightedLength(current+sep+piece) <= limit {
+			current += sep + piece
+			return true
+		}
+		flush()
+		if WeightedLength(piece) <= limit {
+			current = piece
+			return true
+		}
+		return false
+	}
+
+	for _, para := range splitParagraphs(text) {
+		if add(para, "\n\n") {
+			continue
+		}
+		for i, line := range splitLines(para) {
+			lineSep := "\n"
+			if i == 0 {
+				lineSep = "\n\n"
+			}
+			if add(line, lineSep) {
+				continue
+			}
+			for j, sentence := range splitSentences(line) {
+				sep := " "
+				if j == 0 {
+					sep = lineSep
+				}
+				if add(sentence, sep) {
+					continue
+				}
+				for k, word := range strings.Fields(sentence) {
+					wordSep := " "
+					if k == 0 {
+						wordSep = sep
+					}
+					if !add(word, wordSep) {
+						// Longer than a whole post on its own - keep it intact
+						flush()
+						posts = append(posts, word)
+					}
+				}
+			}
+		}
+	}
+	flush()
+
+	return posts
+}
+
+// splitParagraphs splits text on blank lines
+func splitParagraphs(text string) []string {
+	var paras []string
+	for _, p := range paragraphBreak.Split(text, -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			paras = append(paras, p)
+		}
+	}
+	return paras
+}
+
+// splitLines splits a paragraph into its non-empty lines, keeping their
+// indentation for nested lists
+func splitLines(para string) []string {
+	var lines []string
+	for _, line := range strings.Split(para, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return lines
+}
+
+// splitSentences splits a line after sentence-ending punctuation.
+// Links contain dots but no whitespace, so they are never split.
+func splitSentences(para string) []string {
+	var sentences []string
+	last := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(para, -1) {
+		sentences = append(sentences, strings.TrimSpace(para[last:loc[1]]))
+		last = loc[1]
+	}
+	if rest := strings.TrimSpace(para[last:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
@@ -0,0 +1,58 @@
+package x
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitThreadKeepsListLines(t *testing.T) {
+	items := []string{
+		"- Added paging to the commit browser so older history loads as you scroll",
+		"- Added a repo column when reading commits from several repositories",
+		"- Added git hooks that queue feat commits and release tags for a post",
+		"- Fixed thumbnails staying on screen after leaving compose",
+	}
+	text := "Release notes:\n" + strings.Join(items, "\n")
+
+	posts := SplitThread(text, SplitOptions{MaxLength: 160})
+	if len(posts) < 2 {
+		t.Fatalf("expected the list to be split, got %d post(s)", len(posts))
+	}
+	for _, post := range posts {
+		if WeightedLength(post) > 160 {
+			t.Errorf("post over the limit: %q", post)
+		}
+		for _, line := range strings.Split(post, "\n") {
+			if line != "Release notes:" && !strings.HasPrefix(line, "- ") {
+				t.Errorf("list item was flattened or cut: %q", line)
+			}
+		}
+	}
+	if got := strings.Join(posts, "\n"); got != text {
+		t.Errorf("posts don't rejoin to the original text:\n%s", got)
+	}
+}
+
+func TestSplitThreadLongLine(t *testing.T) {
+	line := strings.Repeat("This sentence is part of a line far too long for one post ", 8)
+	text := "Intro\n" + strings.TrimSpace(line) + "\nOutro"
+
+	posts := SplitThread(text, SplitOptions{MaxLength: 100})
+	if len(posts) < 3 {
+		t.Fatalf("expected the long line to be split, got %d post(s)", len(posts))
+	}
+	for _, post := range posts {
+		if WeightedLength(post) > 100 {
+			t.Errorf("post over the limit: %q", post)
+		}
+	}
+	if posts[0] != "Intro" {
+		t.Errorf("first line wasn't kept on its own: %q", posts[0])
+	}
+	if last := posts[len(posts)-1]; !strings.HasSuffix(last, "\nOutro") {
+		t.Errorf("last line wasn't kept on its own line: %q", last)
+	}
+	if got := strings.Join(strings.Fields(strings.Join(posts, " ")), " "); got != strings.Join(strings.Fields(text), " ") {
+		t.Errorf("words were lost or reordered: %q", got)
+	}
+}